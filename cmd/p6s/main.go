@@ -51,6 +51,8 @@ func main() {
 	} else {
 		// Use connection info from config file
 		app.SetConnectionParams(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.Database, cfg.SSLMode)
+		app.SetReadWrite(cfg.ReadWrite)
+		app.SetGuards(cfg.Guards)
 	}
 
 	// Try to connect to database, but continue running even if it fails
@@ -8,6 +8,7 @@ import (
 	"p6s/internal/k8s"
 	"p6s/internal/model"
 	"p6s/internal/ui"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +31,46 @@ type App struct {
 	sslmode    string
 	cmdMode    bool
 	mouseDisabled bool
+	connections []model.Connection
+	lockTree   []model.LockNode
+	lockPID    int
+	topQuerySort int
+	dbStat     *model.DatabaseStat
+	autoRefreshInterval time.Duration
+	autoRefreshStop     chan struct{}
+	idleTxThreshold     time.Duration
+	longTxThreshold     time.Duration
+	tableStats          []model.TableStat
+	tableStatTotal      int
+	tableSort           int
+	tableSortDesc       bool
+	tableSchemaFilter   string
+	tableNameFilter     string
+	tablePage           int
+	bloatSort           int
+	bloatSchema         string
+	bloatTable          string
+	expandedDisplay     bool
+	readWrite           bool
+	resultRows          [][]interface{}
+	resultHeaders       []string
+	customQuery         *db.CustomQuery
+	queryBusy           bool
+	closingQuery        *db.CustomQuery
+	queryResults        [][]interface{}
+	queryHeaders        []string
+	queryMore           bool
+	queryElapsed        time.Duration
+	statementTimeout    time.Duration
+	guards              map[string]bool
+	script              *scriptRun
+	scriptStatement     int
+	scriptContinueOnError bool
+	queryStatement      db.Statement
+	lastSQL             string
+	queryParamValues    map[string]string
+	history             []model.HistoryEntry
+	historyLoaded       bool
 	
 
 	k8sClient  *k8s.K8sClient
@@ -37,14 +78,31 @@ type App struct {
 	stateManager *StateManager
 }
 
+// Column headers of the built-in views
+var (
+	connectionHeaders = []string{"PID", "User", "Database", "Client Address", "Application Name", "Start Time", "Status", "Query"}
+	lockTreeHeaders   = []string{"PID", "Root PID", "User", "Database", "Lock Type", "Lock Mode", "Relation", "Waiting", "Status", "Query"}
+	lockHeaders       = []string{"PID", "Lock Type", "Mode", "Granted", "Relation", "User", "Query Age", "Status", "Query"}
+	replicationHeaders = []string{"Type", "Name", "State", "Sync State", "Write Lag", "Flush Lag", "Replay Lag", "LSN Distance", "Retained WAL"}
+	wraparoundHeaders  = []string{"Type", "Name", "XID Age", "XID %", "MXID Age", "MXID %", "Size"}
+	indexHeaders       = []string{"Table", "Index", "Scans", "Size", "Type", "Issues", "Definition"}
+	vacuumHeaders      = []string{"Table", "Live Tuples", "Dead Tuples", "Dead %", "Vacuum At", "Mods Since Analyze", "Analyze At", "Last Vacuum", "Last Autovacuum", "Last Analyze", "Last Autoanalyze", "Progress"}
+)
+
 // NewApp creates a new application instance
 func NewApp() *App {
 	app := &App{
 		ui:         ui.NewComponents(),
 		db:         db.NewPostgresDB(),
 		filterType: "all",
-		tableHeaders: []string{"PID", "User", "Database", "Client Address", "Application Name", "Start Time", "Status", "Query"},
+		tableHeaders: connectionHeaders,
 		cmdMode:   false,
+		autoRefreshInterval: DefaultAutoRefreshInterval,
+		idleTxThreshold:     DefaultIdleTxThreshold,
+		longTxThreshold:     DefaultLongTxThreshold,
+		tableSortDesc:       tableStatSorts[0].descending,
+		statementTimeout:    DefaultStatementTimeout,
+		scriptStatement:     -1,
 
 		host:     "",
 		port:     "",
@@ -78,21 +136,32 @@ func (a *App) SetConnectionParams(host, port, username, password, database, sslm
 	a.password = password
 	a.database = database
 	a.sslmode = sslmode
-	a.connStr = config.BuildConnStr(host, port, username, password, database, sslmode)
+	a.connStr = config.BuildConnStr(host, port, username, password, database, sslmode, a.readWrite)
 }
 
 // Connect connects to database
 func (a *App) Connect() error {
+	database, err := openDB(a.connStr)
+	return a.useConnection(database, err)
+}
 
-	if a.db != nil {
-		a.db.Close()
-	}
+// openDB connects to the database of connStr. It touches no App state, so it can run in the background.
+func openDB(connStr string) (*db.PostgresDB, error) {
+	database := db.NewPostgresDB()
+	return database, database.Connect(connStr)
+}
+
+// useConnection replaces the connection with database, which openDB returned together with
+// connErr, and loads the current view from it. It must run on the UI goroutine.
+func (a *App) useConnection(database *db.PostgresDB, connErr error) error {
+
+	rolledBack := a.disconnect()
 	
 
-	a.db = db.NewPostgresDB()
+	a.db = database
 	
 
-	if err := a.db.Connect(a.connStr); err != nil {
+	if err := connErr; err != nil {
 
 		a.ui.ConnInfo.SetText(fmt.Sprintf("[red]Connection failed: %v[white]\n", err))
 
@@ -108,12 +177,15 @@ func (a *App) Connect() error {
 	}
 
 
-	a.updateInstanceInfo()
+	a.dbStat = nil
 
 
 	if err := a.refreshData(); err != nil {
 		return err
 	}
+	if rolledBack {
+		a.ShowInfo("The open transaction was rolled back when reconnecting")
+	}
 
 
 	a.ui.App.SetFocus(a.ui.ConnTable)
@@ -122,6 +194,28 @@ func (a *App) Connect() error {
 	return nil
 }
 
+// disconnect closes the open custom query and the database connection. It reports whether an
+// open transaction was rolled back.
+func (a *App) disconnect() bool {
+	// A query still open on the old connection would otherwise keep going on the server
+	a.abortScript()
+	if query := a.customQuery; query != nil && !a.queryBusy {
+		a.customQuery = nil
+		a.queryMore = false
+		query.Close()
+	} else {
+		a.closeCustomQuery()
+	}
+
+	rolledBack := false
+	if a.db != nil {
+		rolledBack = a.db.InTransaction()
+		a.db.Close()
+	}
+	a.updateConnectionMode()
+	return rolledBack
+}
+
 // Run runs the application
 func (a *App) Run() error {
 
@@ -129,24 +223,92 @@ func (a *App) Run() error {
 	a.ui.UpdateFocusStyle()
 	
 
-	return a.ui.App.SetRoot(a.ui.Pages, true).EnableMouse(true).Run()
+	err := a.ui.App.SetRoot(a.ui.Pages, true).EnableMouse(true).Run()
+
+	// Roll back an open transaction instead of leaving it to the server to notice the exit
+	a.disconnect()
+	return err
 }
 
-// updateInstanceInfo updates instance information
-func (a *App) updateInstanceInfo() {
+// viewQuery holds the state needed to load the current view, so it can be captured
+// on the UI goroutine and loaded in the background
+type viewQuery struct {
+	// db is the connection to load from, Connect replaces it on the UI goroutine
+	db         *db.PostgresDB
+	host       string
+	port       string
+	username   string
+	sslmode    string
+	k8sContext string
+
+	filterType      string
+	lockPID         int
+	topQuerySort    int
+	idleTxThreshold time.Duration
+	longTxThreshold time.Duration
+	bloatSort       int
+	bloatSchema     string
+	bloatTable      string
+	tableSort         int
+	tableSortDesc     bool
+	tableSchemaFilter string
+	tableNameFilter   string
+	tablePage         int
+}
 
-	version, err := a.db.GetDatabaseVersion()
+// currentViewQuery captures the state of the current view
+func (a *App) currentViewQuery() viewQuery {
+	k8sContext := "Not connected"
+	if a.k8sConnected {
+		k8sContext = a.k8sClient.GetCurrentContext()
+	}
+
+	return viewQuery{
+		db:         a.db,
+		host:       a.host,
+		port:       a.port,
+		username:   a.username,
+		sslmode:    a.sslmode,
+		k8sContext: k8sContext,
+
+		filterType:      a.filterType,
+		lockPID:         a.lockPID,
+		topQuerySort:    a.topQuerySort,
+		idleTxThreshold: a.idleTxThreshold,
+		longTxThreshold: a.longTxThreshold,
+		bloatSort:       a.bloatSort,
+		bloatSchema:     a.bloatSchema,
+		bloatTable:      a.bloatTable,
+		tableSort:         a.tableSort,
+		tableSortDesc:     a.tableSortDesc,
+		tableSchemaFilter: a.tableSchemaFilter,
+		tableNameFilter:   a.tableNameFilter,
+		tablePage:         a.tablePage,
+	}
+}
+
+// loadInstanceInfo builds instance information text and takes a new pg_stat_database snapshot
+func (a *App) loadInstanceInfo(q viewQuery, database string, prevStat *model.DatabaseStat) (string, *model.DatabaseStat) {
+
+	version, err := q.db.GetDatabaseVersion()
 	if err != nil {
-		a.ui.ConnInfo.SetText(fmt.Sprintf("[red]Failed to get database version: %v[white]\n", err))
-		return
+		return fmt.Sprintf("[red]Failed to get database version: %v[white]\n", err), nil
 	}
 
 
-	k8sContext := ""
-	if a.k8sConnected {
-		k8sContext = a.k8sClient.GetCurrentContext()
+	health := ""
+	stat, err := q.db.GetDatabaseStat()
+	if err != nil {
+		health = fmt.Sprintf("[red]Failed to get database statistics: %v[white]\n", err)
 	} else {
-		k8sContext = "Not connected"
+		health = ui.FormatDatabaseHealth(stat, prevStat)
+	}
+
+	ages, err := q.db.GetDatabaseAges()
+	if err != nil {
+		health += fmt.Sprintf("[red]Failed to get database ages: %v[white]\n", err)
+	} else {
+		health += ui.FormatWraparoundWarning(ages)
 	}
 
 
@@ -158,73 +320,275 @@ func (a *App) updateInstanceInfo() {
 		"Database: %s\n" +
 		"SSL Mode: %s\n\n" +
 		"[yellow]Database Version:[white]\n%s\n\n" +
-		"[yellow]Kubernetes Context:[white]\n%s\n",
-		a.host, a.port, a.username, a.database, a.sslmode, version, k8sContext)
+		"[yellow]Kubernetes Context:[white]\n%s\n\n%s",
+		q.host, q.port, q.username, database, q.sslmode, version, q.k8sContext, health)
 
-	a.ui.ConnInfo.SetText(connInfo)
+	return connInfo, stat
 }
 
 // refreshData refreshes data
 func (a *App) refreshData() error {
 
-	currentDB, err := a.db.GetCurrentDatabase()
+	render, err := a.loadView(a.currentViewQuery(), a.dbStat)
 	if err != nil {
-		a.ui.ConnInfo.SetText(fmt.Sprintf("[red]Failed to get current database: %v[white]\n", err))
+		a.ui.ConnInfo.SetText(fmt.Sprintf("[red]%v[white]\n", err))
 		return err
 	}
 
 
-	a.database = currentDB
+	render()
 
+	return nil
+}
+
+// loadView queries everything the view needs without touching UI components,
+// the returned function renders the result and must run on the UI goroutine
+func (a *App) loadView(q viewQuery, prevStat *model.DatabaseStat) (func(), error) {
+
+	currentDB, err := q.db.GetCurrentDatabase()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get current database: %v", err)
+	}
+
+
+	connInfo, stat := a.loadInstanceInfo(q, currentDB, prevStat)
+
+
+	renderView, err := a.loadViewData(q)
+	if err != nil {
+		return nil, err
+	}
 
-	switch a.filterType {
-	case "all", "active", "blocked":
+
+	return func() {
+		a.database = currentDB
+		a.ui.ConnInfo.SetText(connInfo)
+
+		// Keep the older snapshot on rapid refreshes so rates are not computed over a few milliseconds
+		if stat != nil && (a.dbStat == nil || a.dbStat.Database != stat.Database || stat.CollectedAt.Sub(a.dbStat.CollectedAt) >= time.Second) {
+			a.dbStat = stat
+		}
 
 		a.ui.TableHeaders = a.tableHeaders
+		a.ui.TableStatus = ""
+		renderView()
+		a.ui.UpdateTableTitle()
+	}, nil
+}
 
+// loadViewData queries the rows of the view and returns the function displaying them
+func (a *App) loadViewData(q viewQuery) (func(), error) {
+
+	switch q.filterType {
+	case "all", "active", "blocked":
 
-		connections, err := a.db.GetConnections(a.filterType)
+		connections, err := q.db.GetConnections(q.filterType)
 		if err != nil {
-			a.ui.ConnInfo.SetText(fmt.Sprintf("[red]Failed to get connection info: %v[white]\n", err))
-			return err
+			return nil, fmt.Errorf("Failed to get connection info: %v", err)
 		}
 
-
-		a.ui.DisplayConnections(connections)
+		return func() {
+			a.connections = connections
+			a.ui.DisplayConnections(connections)
+		}, nil
 
 	case "table_size":
 
-		a.ui.TableHeaders = a.tableHeaders
+		filter := q.tableStatsFilter()
+		tableStats, total, err := q.db.GetTableStats(filter)
+		if err == nil && len(tableStats) == 0 && filter.Page > 0 {
+			// The page is gone after relations were dropped or the filter changed
+			filter.Page = 0
+			tableStats, total, err = q.db.GetTableStats(filter)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get table statistics: %v", err)
+		}
+
+		return func() {
+			a.tableStats = tableStats
+			a.tableStatTotal = total
+			a.tablePage = filter.Page
+			a.ui.TableStatus = a.tableStatsStatus()
+			a.ui.DisplayTableStats(tableStats)
+		}, nil
 
+	case "lock_tree":
 
-		tableStats, err := a.db.GetTableStats()
+		lockTree, err := q.db.GetLockTree()
 		if err != nil {
-			a.ui.ConnInfo.SetText(fmt.Sprintf("[red]Failed to get table statistics: %v[white]\n", err))
-			return err
+			return nil, fmt.Errorf("Failed to get lock tree: %v", err)
 		}
 
+		return func() {
+			a.lockTree = lockTree
+			a.ui.DisplayLockTree(lockTree)
+		}, nil
 
-		a.ui.DisplayTableStats(tableStats)
+	case "locks":
+
+		locks, err := q.db.GetLocks(q.lockPID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get locks: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayLocks(locks)
+		}, nil
+
+	case "top_queries":
+
+		installed, err := q.db.HasPgStatStatements()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to check pg_stat_statements: %v", err)
+		}
+		if !installed {
+			return func() {
+				a.ui.DisplayMessage("pg_stat_statements is not installed, run CREATE EXTENSION pg_stat_statements")
+			}, nil
+		}
+
+
+		topQueries, err := q.db.GetTopQueries(topQuerySorts[q.topQuerySort].column)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get top queries: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayTopQueries(topQueries)
+		}, nil
+
+	case "replication":
+
+		status, err := q.db.GetReplicationStatus()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get replication status: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayReplication(status)
+		}, nil
+
+	case "vacuum":
+
+		status, err := q.db.GetVacuumStatus()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get vacuum status: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayVacuumStatus(status)
+		}, nil
+
+	case "wraparound":
+
+		status, err := q.db.GetWraparoundStatus()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get wraparound status: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayWraparound(status)
+		}, nil
+
+	case "indexes":
+
+		report, err := q.db.GetIndexReport()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get index report: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayIndexReport(report)
+		}, nil
+
+	case "bloat":
+
+		estimates, err := q.db.GetBloatEstimates(q.bloatSchema, q.bloatTable, bloatSorts[q.bloatSort].column)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get bloat estimates: %v", err)
+		}
+
+		return func() {
+			a.ui.DisplayBloatEstimates(estimates)
+		}, nil
+
+	case "long_tx":
+
+		connections, err := q.db.GetLongTransactions(q.idleTxThreshold, q.longTxThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get long transactions: %v", err)
+		}
+
+		return func() {
+			a.connections = connections
+			a.ui.DisplayLongTransactions(connections)
+		}, nil
+
+	case "script":
+
+		return func() {
+			a.showScriptResults()
+		}, nil
 
 	case "custom":
 
-		a.ui.TableHeaders = a.tableHeaders
+		return func() {
+			customData := []model.Connection{{
+				PID:             0,
+				User:            sql.NullString{String: "Press ':' key", Valid: true},
+				Database:        sql.NullString{String: "Enter command mode", Valid: true},
+				ClientAddr:      sql.NullString{String: "Execute custom SQL", Valid: true},
+				ApplicationName: sql.NullString{String: "Query", Valid: true},
+				BackendStart:    time.Now(),
+				State:           sql.NullString{String: "Tip", Valid: true},
+				Query:           sql.NullString{String: "Custom query mode", Valid: true},
+			}}
+			a.ui.DisplayConnections(customData)
+		}, nil
+	}
 
+	return func() {}, nil
+}
 
-		customData := []model.Connection{{
-			PID:             0,
-			User:            sql.NullString{String: "Press ':' key", Valid: true},
-		Database:        sql.NullString{String: "Enter command mode", Valid: true},
-		ClientAddr:      sql.NullString{String: "Execute custom SQL", Valid: true},
-		ApplicationName: sql.NullString{String: "Query", Valid: true},
-			BackendStart:    time.Now(),
-			State:           sql.NullString{String: "Tip", Valid: true},
-		Query:           sql.NullString{String: "Custom query mode", Valid: true},
-		}}
-		a.ui.DisplayConnections(customData)
+// switchView switches the result table to the specified view and refreshes it
+func (a *App) switchView(filterType string, headers []string) {
+	// Check database connection before allowing operation
+	if a.db == nil || !a.db.IsConnected() {
+		a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
+		return
+	}
+	// The result cursor keeps a transaction open on the server, the rows read so far stay available
+	if filterType != "custom" && !a.queryBusy {
+		a.closeCustomQuery()
 	}
+	a.filterType = filterType
+	a.tableHeaders = headers
+	a.ui.TableHeaders = a.tableHeaders
+	a.refreshData()
 
-	return nil
+	a.ui.App.SetFocus(a.ui.ConnTable)
+	a.ui.UpdateFocusStyle()
+}
+
+// openSelectedRow opens the detail page of the selected row in the current view
+func (a *App) openSelectedRow() {
+	switch a.filterType {
+	case "all", "active", "blocked", "lock_tree", "long_tx":
+		a.showSessionDetail()
+	case "table_size":
+		a.showTableDetail()
+	case "custom":
+		a.showResultRow()
+	case "script":
+		a.showScriptStatement()
+	}
+}
+
+// showLocks switches to the lock inspector, filtered by PID when pid is not 0
+func (a *App) showLocks(pid int) {
+	a.lockPID = pid
+	a.switchView("locks", lockHeaders)
 }
 
 // setupEventHandlers sets up event handlers
@@ -232,6 +596,11 @@ func (a *App) setupEventHandlers() {
 
 	a.ui.App.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 
+		// Keys typed into the command line must not trigger shortcuts
+		if a.cmdMode {
+			return event
+		}
+
 		if event.Key() == tcell.KeyRune && event.Rune() == ':' {
 
 			a.cmdMode = true
@@ -260,74 +629,28 @@ func (a *App) setupEventHandlers() {
 			}
 			return event
 		}
+
+
+		// View shortcuts only apply to the main page, not to open forms and dialogs
+		if pageName, _ := a.ui.Pages.GetFrontPage(); pageName != "main" {
+			return event
+		}
 		
 
 		if event.Key() == tcell.KeyRune {
 			
 			switch event.Rune() {
 			case '1':
-				// Check database connection before allowing operation
-				if a.db == nil || !a.db.IsConnected() {
-					a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
-					return nil
-				}
-				a.filterType = "all"
-				a.tableHeaders = []string{"PID", "User", "Database", "Client Address", "Application Name", "Start Time", "Status", "Query"}
-				a.ui.TableHeaders = a.tableHeaders
-				if err := a.refreshData(); err != nil {
-	
-				}
-
-				a.ui.App.SetFocus(a.ui.ConnTable)
-				a.ui.UpdateFocusStyle()
+				a.switchView("all", connectionHeaders)
 				return nil
 			case '2':
-				// Check database connection before allowing operation
-				if a.db == nil || !a.db.IsConnected() {
-					a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
-					return nil
-				}
-				a.filterType = "active"
-				a.tableHeaders = []string{"PID", "User", "Database", "Client Address", "Application Name", "Start Time", "Status", "Query"}
-				a.ui.TableHeaders = a.tableHeaders
-				if err := a.refreshData(); err != nil {
-	
-				}
-
-				a.ui.App.SetFocus(a.ui.ConnTable)
-				a.ui.UpdateFocusStyle()
+				a.switchView("active", connectionHeaders)
 				return nil
 			case '3':
-				// Check database connection before allowing operation
-				if a.db == nil || !a.db.IsConnected() {
-					a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
-					return nil
-				}
-				a.filterType = "blocked"
-				a.tableHeaders = []string{"PID", "User", "Database", "Client Address", "Application Name", "Start Time", "Status", "Query"}
-				a.ui.TableHeaders = a.tableHeaders
-				if err := a.refreshData(); err != nil {
-	
-				}
-
-				a.ui.App.SetFocus(a.ui.ConnTable)
-				a.ui.UpdateFocusStyle()
+				a.switchView("blocked", connectionHeaders)
 				return nil
 			case '4':
-				// Check database connection before allowing operation
-				if a.db == nil || !a.db.IsConnected() {
-					a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
-					return nil
-				}
-				a.filterType = "table_size"
-				a.tableHeaders = []string{"Schema", "Table Name", "Total Size", "Table Size", "Index Size", "Total Rows"}
-				a.ui.TableHeaders = a.tableHeaders
-				if err := a.refreshData(); err != nil {
-	
-				}
-
-				a.ui.App.SetFocus(a.ui.ConnTable)
-				a.ui.UpdateFocusStyle()
+				a.showTableStats()
 				return nil
 			case '5':
 				// Check database connection before allowing operation
@@ -336,17 +659,113 @@ func (a *App) setupEventHandlers() {
 					return nil
 				}
 
-				a.showSQLQueryForm()
+				a.showSQLQueryForm("")
 
 				a.ui.App.SetFocus(a.ui.ConnTable)
 				a.ui.UpdateFocusStyle()
 				return nil
+			case '6':
+				a.switchView("lock_tree", lockTreeHeaders)
+				return nil
+			case '7':
+				a.showLocks(0)
+				return nil
+			case '8':
+				a.showTopQueries()
+				return nil
+			case '9':
+				a.switchView("replication", replicationHeaders)
+				return nil
+			case '0':
+				a.showLongTransactions()
+				return nil
+			case 'V':
+				a.switchView("vacuum", vacuumHeaders)
+				return nil
+			case 'W':
+				a.switchView("wraparound", wraparoundHeaders)
+				return nil
+			case 'I':
+				a.switchView("indexes", indexHeaders)
+				return nil
+			case 'B':
+				a.showSelectedTableBloat()
+				return nil
+			case 'D':
+				a.showSchemaBrowser()
+				return nil
 			}
 		}
 		return event
 	})
 
 
+	a.ui.HeaderClicked = a.sortByHeader
+
+	a.ui.App.SetAfterDrawFunc(func(screen tcell.Screen) {
+		a.loadMoreOnScroll()
+	})
+
+	a.ui.ConnTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			a.openSelectedRow()
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape && a.filterType == "custom" && a.scriptStatement >= 0 {
+			a.showScriptResults()
+			return nil
+		}
+		if event.Key() == tcell.KeyRune {
+			switch event.Rune() {
+			case 'C':
+				if a.queryBusy && a.script != nil && !a.script.done {
+					a.cancelScript()
+				} else if a.queryBusy && a.filterType == "custom" {
+					a.cancelCustomQuery()
+				} else {
+					a.confirmBackendAction(false)
+				}
+				return nil
+			case 'K':
+				a.confirmBackendAction(true)
+				return nil
+			case 'L':
+				if conn, ok := a.selectedConnection(); ok {
+					a.showLocks(conn.PID)
+				}
+				return nil
+			case 'S':
+				switch a.filterType {
+				case "top_queries":
+					a.nextTopQuerySort()
+				case "bloat":
+					a.nextBloatSort()
+				case "table_size":
+					a.nextTableSort()
+				}
+				return nil
+			case 'R':
+				a.toggleAutoRefresh()
+				return nil
+			case 'N', 'P':
+				if a.filterType == "table_size" {
+					if event.Rune() == 'N' {
+						a.changeTablePage(1)
+					} else {
+						a.changeTablePage(-1)
+					}
+				}
+				return nil
+			case 'X':
+				if a.filterType == "long_tx" {
+					a.confirmBulkTerminate()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
 
 	a.ui.CmdInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if event.Key() == tcell.KeyEnter {
@@ -360,6 +779,10 @@ func (a *App) setupEventHandlers() {
 			a.ui.FlexBox.ResizeItem(a.ui.CmdInput, 0, 0)
 			a.ui.App.SetFocus(a.ui.ConnTable)
 			return nil
+		} else if event.Key() == tcell.KeyTab {
+
+			a.completeCommand()
+			return nil
 		} else if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
 			text := a.ui.CmdInput.GetText()
 	
@@ -379,7 +802,10 @@ func (a *App) setupEventHandlers() {
 // handleCommand handles commands
 func (a *App) handleCommand(cmd string) {
 
-	if strings.HasPrefix(cmd, "\\c") && !strings.HasPrefix(cmd, "\\config") {
+	if isMetaCommand(cmd) {
+
+		a.handleMetaCommand(cmd)
+	} else if cmd == "\\c" || strings.HasPrefix(cmd, "\\c ") {
 
 		parts := strings.Fields(cmd)
 		if len(parts) > 1 {
@@ -388,7 +814,7 @@ func (a *App) handleCommand(cmd string) {
 			
 			a.database = dbName
 			
-			a.connStr = config.BuildConnStr(a.host, a.port, a.username, a.password, a.database, a.sslmode)
+			a.connStr = config.BuildConnStr(a.host, a.port, a.username, a.password, a.database, a.sslmode, a.readWrite)
 
 			if err := a.Connect(); err != nil {
 
@@ -405,6 +831,52 @@ func (a *App) handleCommand(cmd string) {
 	
 			a.showDatabaseSelectionForm(databases)
 		}
+	} else if cmd == "\\locks" || strings.HasPrefix(cmd, "\\locks ") {
+
+		parts := strings.Fields(cmd)
+		if len(parts) > 1 {
+			pid, err := strconv.Atoi(parts[1])
+			if err != nil {
+				a.ShowError(fmt.Sprintf("Invalid PID: %s", parts[1]))
+				return
+			}
+			a.showLocks(pid)
+		} else {
+			a.showLocks(0)
+		}
+	} else if cmd == "\\tables" || strings.HasPrefix(cmd, "\\tables ") {
+
+		a.handleTablesCommand(cmd)
+	} else if cmd == "\\longtx" || strings.HasPrefix(cmd, "\\longtx ") {
+
+		a.handleLongTxCommand(cmd)
+	} else if cmd == "\\rw" || strings.HasPrefix(cmd, "\\rw ") {
+
+		a.handleReadWriteCommand(cmd)
+	} else if cmd == "\\begin" || cmd == "\\commit" || cmd == "\\rollback" {
+
+		a.handleTransactionCommand(cmd, "")
+	} else if cmd == "\\timeout" || strings.HasPrefix(cmd, "\\timeout ") {
+
+		a.handleTimeoutCommand(cmd)
+	} else if cmd == "\\onerror" || strings.HasPrefix(cmd, "\\onerror ") {
+
+		a.handleOnErrorCommand(cmd)
+	} else if cmd == "\\history" || strings.HasPrefix(cmd, "\\history ") {
+
+		a.handleHistoryCommand(cmd)
+	} else if cmd == "\\qsave" || strings.HasPrefix(cmd, "\\qsave ") {
+
+		a.handleSaveQueryCommand(cmd)
+	} else if cmd == "\\qdel" || strings.HasPrefix(cmd, "\\qdel ") {
+
+		a.handleDeleteQueryCommand(cmd)
+	} else if cmd == "\\q" || strings.HasPrefix(cmd, "\\q ") {
+
+		a.handleSavedQueryCommand(cmd)
+	} else if cmd == "refresh" || strings.HasPrefix(cmd, "refresh ") || cmd == "\\refresh" || strings.HasPrefix(cmd, "\\refresh ") {
+
+		a.handleRefreshCommand(cmd)
 	} else if cmd == "\\config" {
 
 		a.showConfigForm()
@@ -511,7 +983,7 @@ func (a *App) showDatabaseSelectionForm(databases []string) {
 			}
 			a.database = selectedDB
 			
-			a.connStr = config.BuildConnStr(a.host, a.port, a.username, a.password, a.database, a.sslmode)
+			a.connStr = config.BuildConnStr(a.host, a.port, a.username, a.password, a.database, a.sslmode, a.readWrite)
 			
 			if err := a.Connect(); err != nil {
 				
@@ -558,8 +1030,8 @@ func (a *App) showDatabaseSelectionForm(databases []string) {
 	}()
 }
 
-// showSQLQueryForm shows SQL query window
-func (a *App) showSQLQueryForm() {
+// showSQLQueryForm shows SQL query window with the given statement
+func (a *App) showSQLQueryForm(text string) {
 
 	form := tview.NewForm()
 
@@ -571,6 +1043,7 @@ func (a *App) showSQLQueryForm() {
 	sqlTextArea.SetWordWrap(true)
 
 	sqlTextArea.SetMaxLength(50000)
+	sqlTextArea.SetText(text, true)
 
 	sqlTextArea.SetChangedFunc(nil)
 
@@ -606,6 +1079,14 @@ func (a *App) showSQLQueryForm() {
 			return nil
 		}
 
+		// Recall a statement from the query history
+		if event.Key() == tcell.KeyCtrlR {
+			a.showHistory("", sqlTextArea, func(sqlQuery string) {
+				sqlTextArea.SetText(sqlQuery, true)
+			})
+			return nil
+		}
+
 		if event.Rune() >= '1' && event.Rune() <= '5' {
 			return event
 		}
@@ -729,45 +1210,6 @@ func (a *App) closeSQLQueryForm() {
 	a.ui.App.SetFocus(a.ui.ConnTable)
 }
 
-// executeCustomSQL executes custom SQL query
-func (a *App) executeCustomSQL(sqlQuery string) {
-
-	if a.db == nil {
-
-		errorHeaders := []string{"Error"}
-		errorResults := [][]interface{}{{"Not connected to database"}}
-		a.filterType = "custom"
-		a.tableHeaders = errorHeaders
-		a.ui.TableHeaders = errorHeaders
-		a.ui.DisplayCustomQueryResults(errorResults, errorHeaders)
-		return
-	}
-
-
-	results, headers, err := a.db.ExecuteCustomQuery(sqlQuery)
-	if err != nil {
-
-		errorHeaders := []string{"Error"}
-		errorResults := [][]interface{}{{fmt.Sprintf("SQL query execution failed: %v", err)}}
-		a.filterType = "custom"
-		a.tableHeaders = errorHeaders
-		a.ui.TableHeaders = errorHeaders
-		a.ui.DisplayCustomQueryResults(errorResults, errorHeaders)
-		return
-	}
-
-
-	a.filterType = "custom"
-	a.tableHeaders = headers
-	a.ui.TableHeaders = headers
-
-
-	a.ui.DisplayCustomQueryResults(results, headers)
-
-
-
-}
-
 // ShowError displays error information
 func (a *App) ShowError(message string) {
 	a.ui.ConnInfo.SetText(fmt.Sprintf("[red]%s[white]\n", message))
@@ -801,32 +1243,46 @@ func (a *App) showConfigForm() {
 	databaseField.SetFieldTextColor(tcell.ColorWhite)
 	databaseField.SetFieldBackgroundColor(tcell.ColorBlack)
 
+	// Read-write is a setting of the saved profile, \rw only changes the current session
+	profileReadWrite := false
+	if existingConfig, err := config.LoadConfig(); err == nil {
+		profileReadWrite = existingConfig.ReadWrite
+	}
+	form.AddCheckbox("Read-write", profileReadWrite, nil)
+	readWriteField := form.GetFormItem(5).(*tview.Checkbox)
+	readWriteField.SetFieldTextColor(tcell.ColorWhite)
+	readWriteField.SetFieldBackgroundColor(tcell.ColorBlack)
+
 
 	// Add buttons
 	form.AddButton("Save", func() {
+		// Get values from form
+		host := form.GetFormItem(0).(*tview.InputField).GetText()
+		port := form.GetFormItem(1).(*tview.InputField).GetText()
+		username := form.GetFormItem(2).(*tview.InputField).GetText()
+		password := form.GetFormItem(3).(*tview.InputField).GetText()
+		database := form.GetFormItem(4).(*tview.InputField).GetText()
+		readWrite := form.GetFormItem(5).(*tview.Checkbox).IsChecked()
+		sslmode := "disable" // Hardcode SSL Mode to disable
+
 		// Immediately remove page and set focus to avoid UI freeze
 		a.ui.Pages.RemovePage("config")
 		a.ui.App.SetFocus(a.ui.ConnTable)
+
+		// Update application connection parameters
+		a.host = host
+		a.port = port
+		a.username = username
+		a.password = password
+		a.database = database
+		a.sslmode = sslmode
+		a.readWrite = readWrite
+		a.connStr = config.BuildConnStr(host, port, username, password, database, sslmode, readWrite)
+		connStr := a.connStr
 		
-		// Use goroutine to handle time-consuming operations to avoid blocking UI
+		// Use goroutine to handle time-consuming operations to avoid blocking UI, the
+		// old connection is only replaced on the UI goroutine
 		go func() {
-			// Get values from form
-			host := form.GetFormItem(0).(*tview.InputField).GetText()
-			port := form.GetFormItem(1).(*tview.InputField).GetText()
-			username := form.GetFormItem(2).(*tview.InputField).GetText()
-			password := form.GetFormItem(3).(*tview.InputField).GetText()
-			database := form.GetFormItem(4).(*tview.InputField).GetText()
-			sslmode := "disable" // Hardcode SSL Mode to disable
-
-			// Update application connection parameters
-			a.host = host
-			a.port = port
-			a.username = username
-			a.password = password
-			a.database = database
-			a.sslmode = sslmode
-			a.connStr = config.BuildConnStr(host, port, username, password, database, sslmode)
-
 			// Load existing config to preserve K8s related fields
 			existingConfig, _ := config.LoadConfig()
 			
@@ -838,6 +1294,7 @@ func (a *App) showConfigForm() {
 				Password: password,
 				Database: database,
 				SSLMode:  sslmode,
+				ReadWrite: readWrite,
 			}
 			
 			// If K8s config exists, preserve these fields
@@ -848,6 +1305,7 @@ func (a *App) showConfigForm() {
 				cfg.PortName = existingConfig.PortName
 				cfg.Secret = existingConfig.Secret
 				cfg.SecretKey = existingConfig.SecretKey
+				cfg.Guards = existingConfig.Guards
 			}
 
 			var saveErr, connErr, refreshErr error
@@ -856,17 +1314,25 @@ func (a *App) showConfigForm() {
 			saveErr = config.SaveConfig(cfg)
 			
 			// Try to connect to database
+			var newDB *db.PostgresDB
 			if saveErr == nil {
-				connErr = a.Connect()
-			}
-			
-			// Refresh data
-			if connErr == nil {
-				refreshErr = a.refreshData()
+				newDB, connErr = openDB(connStr)
 			}
 			
 			// Use QueueUpdateDraw to update UI
 			a.ui.App.QueueUpdateDraw(func() {
+				if newDB != nil {
+					// Another connection was made meanwhile
+					if a.connStr != connStr {
+						newDB.Close()
+						return
+					}
+					// Replace the connection and refresh data
+					if err := a.useConnection(newDB, connErr); err != nil && connErr == nil {
+						refreshErr = err
+					}
+				}
+
 				// Get current connection info text
 				currentText := a.ui.ConnInfo.GetText(true) // true means get raw text including color tags
 				
@@ -1002,13 +1468,13 @@ func (a *App) showConfigForm() {
 	modalRow.AddItem(form, 50, 1, true) // Form
 	modalRow.AddItem(nil, 0, 1, false) // Right margin
 
-	modal.AddItem(modalRow, 18, 1, true) // Form row, increase height from 15 to 18
+	modal.AddItem(modalRow, 20, 1, true) // Form row, two lines per field plus buttons
 	modal.AddItem(nil, 0, 1, false) // Bottom margin
 
 	// Create a centered container
 	center := tview.NewFlex().SetDirection(tview.FlexRow)
 	center.AddItem(nil, 0, 1, false)
-	center.AddItem(modal, 18, 1, true) // Increase height from 15 to 18
+	center.AddItem(modal, 20, 1, true)
 	center.AddItem(nil, 0, 1, false)
 
 	// First remove any existing old page, then add new modal dialog
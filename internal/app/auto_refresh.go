@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAutoRefreshInterval is used when auto-refresh is toggled on without an explicit interval
+const DefaultAutoRefreshInterval = 5 * time.Second
+
+// isAutoRefreshable reports whether a view shows live data that can be re-queried periodically
+func isAutoRefreshable(filterType string) bool {
+	switch filterType {
+	case "custom", "script", "k8s":
+		return false
+	}
+	return true
+}
+
+// toggleAutoRefresh switches auto-refresh on or off with the configured interval
+func (a *App) toggleAutoRefresh() {
+	if a.autoRefreshStop != nil {
+		a.stopAutoRefresh()
+		return
+	}
+	a.startAutoRefresh(a.autoRefreshInterval)
+}
+
+// startAutoRefresh (re)starts the background refresh loop
+func (a *App) startAutoRefresh(interval time.Duration) {
+	a.stopAutoRefresh()
+
+	a.autoRefreshInterval = interval
+	stop := make(chan struct{})
+	a.autoRefreshStop = stop
+	a.ui.AutoRefresh = interval
+	a.ui.UpdateFocusStyle()
+
+	go a.autoRefreshLoop(interval, stop)
+}
+
+// stopAutoRefresh stops the background refresh loop if running
+func (a *App) stopAutoRefresh() {
+	if a.autoRefreshStop != nil {
+		close(a.autoRefreshStop)
+		a.autoRefreshStop = nil
+	}
+	a.ui.AutoRefresh = 0
+	a.ui.UpdateFocusStyle()
+}
+
+// autoRefreshLoop re-runs the current view query on every tick until stop is closed
+func (a *App) autoRefreshLoop(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.autoRefreshOnce(stop)
+		}
+	}
+}
+
+// autoRefreshOnce loads the current view off the UI goroutine and renders it,
+// keeping the selected row and scroll position
+func (a *App) autoRefreshOnce(stop chan struct{}) {
+	var q viewQuery
+	var prevStat *model.DatabaseStat
+	paused := false
+
+	// Capture the view state and connection on the UI goroutine, Connect replaces a.db there
+	a.ui.App.QueueUpdate(func() {
+		pageName, _ := a.ui.Pages.GetFrontPage()
+		paused = pageName != "main" || a.cmdMode || a.db == nil || !a.db.IsConnected() || !isAutoRefreshable(a.filterType)
+		q = a.currentViewQuery()
+		prevStat = a.dbStat
+	})
+	if paused {
+		return
+	}
+
+	render, err := a.loadView(q, prevStat)
+
+	a.ui.App.QueueUpdateDraw(func() {
+		// Drop results when refresh was stopped or the view or connection changed while loading
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if a.currentViewQuery() != q {
+			return
+		}
+		if err != nil {
+			a.ShowError(err.Error())
+			return
+		}
+
+		row, column := a.ui.ConnTable.GetSelection()
+		rowOffset, columnOffset := a.ui.ConnTable.GetOffset()
+
+		render()
+
+		if row >= a.ui.ConnTable.GetRowCount() {
+			row = a.ui.ConnTable.GetRowCount() - 1
+		}
+		if row >= 1 {
+			a.ui.ConnTable.Select(row, column)
+			a.ui.ConnTable.SetOffset(rowOffset, columnOffset)
+		}
+	})
+}
+
+// handleRefreshCommand handles "refresh <seconds>|on|off"
+func (a *App) handleRefreshCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	if len(parts) < 2 {
+		a.toggleAutoRefresh()
+		return
+	}
+
+	switch parts[1] {
+	case "off", "0":
+		a.stopAutoRefresh()
+	case "on":
+		a.startAutoRefresh(a.autoRefreshInterval)
+	default:
+		seconds, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || seconds < 0.5 {
+			a.ShowError(fmt.Sprintf("Invalid refresh interval: %s (seconds, minimum 0.5)", parts[1]))
+			return
+		}
+		a.startAutoRefresh(time.Duration(seconds * float64(time.Second)))
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"p6s/internal/db"
+)
+
+// bloatHeaders are the column headers of the bloat view
+var bloatHeaders = []string{"Type", "Table", "Index", "Real Size", "Bloat Size", "Bloat %", "Fillfactor", "Note"}
+
+// bloatSorts lists sortable columns of the bloat view in switching order
+var bloatSorts = []struct {
+	column string
+	header int
+}{
+	{db.SortByBloatSize, 4},
+	{db.SortByBloatPercent, 5},
+	{db.SortByRealSize, 3},
+}
+
+// showBloat switches to the bloat view, restricted to a single table when schema and table are not empty
+func (a *App) showBloat(schema, table string) {
+	a.bloatSchema = schema
+	a.bloatTable = table
+	a.switchView("bloat", markSortedHeader(bloatHeaders, bloatSorts[a.bloatSort].header))
+}
+
+// showSelectedTableBloat opens the bloat view of the table selected in the table statistics view,
+// or of all tables from any other view
+func (a *App) showSelectedTableBloat() {
+	row, _ := a.ui.ConnTable.GetSelection()
+	if a.filterType == "table_size" && row >= 1 && row <= len(a.tableStats) {
+		stat := a.tableStats[row-1]
+		a.showBloat(stat.Schema, stat.Name)
+		return
+	}
+	a.showBloat("", "")
+}
+
+// nextBloatSort switches the bloat view to the next sort column
+func (a *App) nextBloatSort() {
+	a.bloatSort = (a.bloatSort + 1) % len(bloatSorts)
+	a.showBloat(a.bloatSchema, a.bloatTable)
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"p6s/internal/config"
+	"p6s/internal/db"
 )
 
 // ConfigManager configuration manager
@@ -81,6 +82,9 @@ func (cm *ConfigManager) SaveAndConnect(connConfig *ConnectionConfig, onComplete
 		return
 	}
 	
+	// Connection parameters are read and the connection is replaced on the UI goroutine
+	readWrite := cm.app.readWrite
+
 	// Use goroutine to handle time-consuming operations
 	go func() {
 		// If K8s Secret is used, need to get actual password value
@@ -91,9 +95,6 @@ func (cm *ConfigManager) SaveAndConnect(connConfig *ConnectionConfig, onComplete
 				actualPassword = secretPassword
 			}
 		}
-
-		// Update app connection parameters (using actual password)
-		cm.updateAppConfigWithPassword(connConfig, actualPassword)
 		
 		// Create configuration object
 		cfg := &config.Config{
@@ -111,31 +112,43 @@ func (cm *ConfigManager) SaveAndConnect(connConfig *ConnectionConfig, onComplete
 			Secret:    connConfig.Secret,
 			SecretKey: connConfig.SecretKey,
 		}
-		
-		var finalError error
+		// Keep the read-write and guard settings of the profile
+		if existingConfig, err := config.LoadConfig(); err == nil {
+			cfg.ReadWrite = existingConfig.ReadWrite
+			cfg.Guards = existingConfig.Guards
+		}
 		
 		// Save configuration to file
-		if err := config.SaveConfig(cfg); err != nil {
-			cm.errorHandler.HandleError(err, "Save configuration")
-			finalError = err
-		} else {
-			// Try to connect to database
-			if err := cm.app.Connect(); err != nil {
-				cm.errorHandler.HandleError(err, "Connect to database")
-				finalError = err
-			} else {
-				// Refresh data
-				if err := cm.app.refreshData(); err != nil {
-					cm.errorHandler.HandleError(err, "Refresh data")
-					finalError = err
-				} else {
-			
-				}
-			}
+		saveErr := config.SaveConfig(cfg)
+
+		// Try to connect to database
+		var newDB *db.PostgresDB
+		var connErr error
+		if saveErr == nil {
+			connStr := config.BuildConnStr(connConfig.Host, connConfig.Port, connConfig.Username, actualPassword,
+				connConfig.Database, connConfig.SSLMode, readWrite)
+			newDB, connErr = openDB(connStr)
 		}
 		
 		// Use QueueUpdateDraw to update UI
 		cm.app.ui.App.QueueUpdateDraw(func() {
+			var finalError error
+
+			// Update app connection parameters (using actual password)
+			cm.updateAppConfigWithPassword(connConfig, actualPassword)
+
+			if saveErr != nil {
+				cm.errorHandler.HandleError(saveErr, "Save configuration")
+				finalError = saveErr
+			} else if err := cm.app.useConnection(newDB, connErr); err != nil {
+				if connErr != nil {
+					cm.errorHandler.HandleError(err, "Connect to database")
+				} else {
+					cm.errorHandler.HandleError(err, "Refresh data")
+				}
+				finalError = err
+			}
+
 			if onComplete != nil {
 				onComplete(finalError)
 			}
@@ -166,6 +179,7 @@ func (cm *ConfigManager) updateAppConfigWithPassword(connConfig *ConnectionConfi
 		password, // Use actual password to build connection string
 		connConfig.Database,
 		connConfig.SSLMode,
+		cm.app.readWrite,
 	)
 }
 
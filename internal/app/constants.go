@@ -18,6 +18,16 @@ const (
 	// Page names
 	K8sConfigPageName = "k8s_config"
 	SQLQueryPageName  = "sql_query"
+	ConfirmActionPageName = "confirm_action"
+	SessionDetailPageName = "session_detail"
+	BulkTerminatePageName = "bulk_terminate"
+	TableDetailPageName   = "table_detail"
+	SchemaBrowserPageName = "schema_browser"
+	ResultRowPageName     = "result_row"
+	ConfirmSQLPageName    = "confirm_sql"
+	HistoryPageName       = "query_history"
+	SavedQueriesPageName  = "saved_queries"
+	QueryParamsPageName   = "query_params"
 )
 
 // Color constants
@@ -0,0 +1,379 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"p6s/internal/db"
+	"p6s/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStatementTimeout is the statement_timeout of custom SQL queries
+const DefaultStatementTimeout = 30 * time.Second
+
+// QueryPageSize is the number of result rows fetched at once, further pages are loaded on scrolling
+const QueryPageSize = 500
+
+// queryLoadAheadRows is how close to the last fetched row scrolling loads the next page
+const queryLoadAheadRows = 20
+
+// spinnerFrames are shown in the result table title while a custom query runs
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// executeCustomSQL runs a custom SQL query in the background, showing its progress in the result table title
+func (a *App) executeCustomSQL(sqlQuery string) {
+	a.lastSQL = sqlQuery
+	a.executeStatements(db.SplitStatements(sqlQuery))
+}
+
+// executeStatements runs the statements of custom SQL or of a saved query after checking that they can
+// run now and asking for confirmation of the guarded ones
+func (a *App) executeStatements(statements []db.Statement) {
+	if a.db == nil {
+		a.showQueryError("Not connected to database")
+		return
+	}
+	if a.queryBusy {
+		a.ShowError("A query is already running, press C in the result table to cancel it")
+		return
+	}
+	if a.connectionClosing() {
+		return
+	}
+	if len(statements) == 0 {
+		a.ShowError("There is no SQL statement to run")
+		return
+	}
+	if statement, ok := a.readOnlyViolation(statements); ok {
+		a.ShowError("The session is read-only, switch to read-write mode with \\rw on to run: " + statementPreview(statement.Text))
+		return
+	}
+	if len(statements) == 1 && len(statements[0].Args) == 0 {
+		if command, statement, ok := transactionStatement(statements[0].Text); ok {
+			a.handleTransactionCommand(command, statement)
+			return
+		}
+	}
+	if guarded := a.guardedStatements(statements); len(guarded) > 0 {
+		a.confirmGuardedSQL(statements, guarded)
+		return
+	}
+
+	a.runStatements(statements)
+}
+
+// runStatements starts the statements once they passed the checks of executeStatements, a script of
+// several statements runs statement by statement
+func (a *App) runStatements(statements []db.Statement) {
+	if a.queryBusy {
+		a.ShowError("A query is already running, press C in the result table to cancel it")
+		return
+	}
+	if a.connectionClosing() {
+		return
+	}
+	if len(statements) > 1 {
+		a.runScript(statements)
+		return
+	}
+	statement := statements[0]
+
+	a.closeCustomQuery()
+	query := a.db.NewCustomQuery(a.statementTimeout)
+	a.customQuery = query
+	a.queryStatement = statement
+	a.queryResults = nil
+	a.queryHeaders = nil
+	a.queryElapsed = 0
+	a.resultRows = nil
+
+	a.filterType = "custom"
+	a.tableHeaders = []string{"Status"}
+	a.ui.TableHeaders = a.tableHeaders
+	a.ui.DisplayCustomQueryResults([][]interface{}{{"Running query..."}}, a.tableHeaders)
+
+	a.runQueryStep(query, func() ([][]interface{}, []string, bool, error) {
+		return query.Run(statement.Text, QueryPageSize, statement.Args...)
+	})
+}
+
+// fetchMoreResults loads the next page of the open custom query
+func (a *App) fetchMoreResults() {
+	query := a.customQuery
+	if query == nil || a.queryBusy || !a.queryMore {
+		return
+	}
+
+	a.runQueryStep(query, func() ([][]interface{}, []string, bool, error) {
+		results, more, err := query.Fetch(QueryPageSize)
+		return results, nil, more, err
+	})
+}
+
+// loadMoreOnScroll fetches the next page once the result table is scrolled close to the last fetched row.
+// It runs after every draw, so it also closes the open query once another view was selected.
+func (a *App) loadMoreOnScroll() {
+	if a.filterType != "custom" {
+		a.closeCustomQuery()
+		return
+	}
+	if !a.queryMore || a.queryBusy {
+		return
+	}
+
+	row, _ := a.ui.ConnTable.GetSelection()
+	rowOffset, _ := a.ui.ConnTable.GetOffset()
+	_, _, _, height := a.ui.ConnTable.GetInnerRect()
+	last := a.ui.ConnTable.GetRowCount() - 1
+
+	if row >= last-queryLoadAheadRows || rowOffset+height >= last-queryLoadAheadRows {
+		a.fetchMoreResults()
+	}
+}
+
+// runQueryStep runs Run or Fetch of the custom query in the background while animating the progress
+func (a *App) runQueryStep(query *db.CustomQuery, step func() ([][]interface{}, []string, bool, error)) {
+	a.queryBusy = true
+	started := time.Now()
+	a.ui.TableStatus = a.queryProgress(0, 0)
+	a.ui.UpdateTableTitle()
+
+	done := make(chan struct{})
+	go a.progressLoop(started, done, func(frame int, elapsed time.Duration) {
+		if a.customQuery != query || !a.queryBusy || a.filterType != "custom" {
+			return
+		}
+		a.ui.TableStatus = a.queryProgress(frame, elapsed)
+		a.ui.UpdateTableTitle()
+	})
+
+	go func() {
+		results, headers, more, err := step()
+		close(done)
+		elapsed := time.Since(started)
+
+		a.ui.App.QueueUpdateDraw(func() {
+			a.finishQueryStep(query, results, headers, more, err, elapsed)
+		})
+	}()
+}
+
+// progressLoop animates the spinner and elapsed time until done is closed, update runs on the UI goroutine
+func (a *App) progressLoop(started time.Time, done chan struct{}, update func(frame int, elapsed time.Duration)) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for frame := 1; ; frame++ {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			current, elapsed := frame, time.Since(started)
+			a.ui.App.QueueUpdateDraw(func() {
+				update(current, elapsed)
+			})
+		}
+	}
+}
+
+// finishQueryStep shows the rows of a finished Run or Fetch unless the query was closed meanwhile
+func (a *App) finishQueryStep(query *db.CustomQuery, results [][]interface{}, headers []string, more bool, err error, elapsed time.Duration) {
+	if a.customQuery != query {
+		// Closing was left to this step, see closeCustomQuery
+		if query == a.closingQuery {
+			a.closingQuery = nil
+			query.Close()
+			return
+		}
+		go query.Close()
+		return
+	}
+	a.queryBusy = false
+	a.queryElapsed += elapsed
+	// A failing statement aborts the open transaction
+	a.updateConnectionMode()
+	a.queryMore = more && err == nil
+	if !a.queryMore {
+		// Fetch closes the query when all rows were read or it failed
+		a.customQuery = nil
+	}
+
+	firstPage := a.queryHeaders == nil
+	if firstPage {
+		entry := model.HistoryEntry{
+			SQL:      a.queryStatement.Source(),
+			Params:   a.queryStatement.Params,
+			Rows:     int64(len(results)),
+			MoreRows: more,
+		}
+		if affected, ok := query.RowsAffected(); ok {
+			entry.Rows, entry.Affected = affected, true
+		}
+		a.recordHistory(entry, elapsed, err)
+	}
+
+	if err != nil {
+		message := fmt.Sprintf("SQL query execution failed: %v", err)
+		if errors.Is(err, db.ErrQueryCancelled) {
+			message = fmt.Sprintf("Query cancelled after %s", formatElapsed(a.queryElapsed))
+		}
+		if firstPage {
+			a.showQueryError(message)
+			a.ui.UpdateTableTitle()
+		} else {
+			a.ShowError(message)
+			a.ui.TableStatus = a.queryResultStatus()
+			a.ui.UpdateTableTitle()
+		}
+		return
+	}
+
+	if affected, ok := query.RowsAffected(); ok {
+		a.showResults([][]interface{}{{fmt.Sprintf("%d rows affected", affected)}}, []string{"Result"})
+		a.ui.TableStatus = fmt.Sprintf("%d rows affected in %s", affected, formatElapsed(a.queryElapsed))
+		a.ui.UpdateTableTitle()
+		return
+	}
+
+	if firstPage {
+		a.queryHeaders = headers
+	}
+	a.queryResults = append(a.queryResults, results...)
+
+	row, column := a.ui.ConnTable.GetSelection()
+	rowOffset, columnOffset := a.ui.ConnTable.GetOffset()
+
+	a.showResults(a.queryResults, a.queryHeaders)
+
+	// Appended pages keep the position the user scrolled to
+	if !firstPage {
+		a.ui.ConnTable.Select(row, column)
+		a.ui.ConnTable.SetOffset(rowOffset, columnOffset)
+	}
+	a.ui.TableStatus = a.queryResultStatus()
+	a.ui.UpdateTableTitle()
+}
+
+// cancelCustomQuery cancels the running custom query client- and server-side
+func (a *App) cancelCustomQuery() {
+	query := a.customQuery
+	if query == nil || !a.queryBusy {
+		return
+	}
+
+	a.ui.TableStatus = "cancelling..."
+	a.ui.UpdateTableTitle()
+
+	go func() {
+		if err := query.Cancel(); err != nil {
+			a.ui.App.QueueUpdateDraw(func() {
+				a.ShowError(err.Error())
+			})
+		}
+	}()
+}
+
+// closeCustomQuery releases the connection of the open custom query, cancelling it when running.
+// A query on the connection of the transaction or script is closed before anything else runs on it.
+func (a *App) closeCustomQuery() {
+	query := a.customQuery
+	if query == nil {
+		return
+	}
+	a.customQuery = nil
+	a.queryMore = false
+
+	if a.queryBusy {
+		// finishQueryStep closes the query once Run or Fetch returned
+		a.queryBusy = false
+		if query.Borrowed() {
+			a.closingQuery = query
+		}
+		go query.Cancel()
+		return
+	}
+	if query.Borrowed() {
+		query.Close()
+		return
+	}
+	go query.Close()
+}
+
+// connectionClosing reports, with an error, that a cancelled query still runs on the connection of
+// the transaction, which takes no new statement until finishQueryStep closed the query
+func (a *App) connectionClosing() bool {
+	if a.closingQuery == nil {
+		return false
+	}
+	a.ShowError("The cancelled query is still finishing on the transaction connection, try again in a moment")
+	return true
+}
+
+// showQueryError shows a custom query error in the result table
+func (a *App) showQueryError(message string) {
+	errorHeaders := []string{"Error"}
+	a.resultRows = nil
+	a.filterType = "custom"
+	a.tableHeaders = errorHeaders
+	a.ui.TableHeaders = errorHeaders
+	a.ui.TableStatus = ""
+	a.ui.DisplayCustomQueryResults([][]interface{}{{message}}, errorHeaders)
+}
+
+// handleTimeoutCommand handles "\timeout [seconds|off]"
+func (a *App) handleTimeoutCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	if len(parts) < 2 {
+		a.ShowInfo(fmt.Sprintf("Statement timeout: %s", formatStatementTimeout(a.statementTimeout)))
+		return
+	}
+
+	switch parts[1] {
+	case "off", "0":
+		a.statementTimeout = 0
+	default:
+		seconds, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || seconds < 0.001 {
+			a.ShowError(fmt.Sprintf("Invalid statement timeout: %s (seconds or off)", parts[1]))
+			return
+		}
+		a.statementTimeout = time.Duration(seconds * float64(time.Second))
+	}
+	a.ShowInfo(fmt.Sprintf("Statement timeout set to %s", formatStatementTimeout(a.statementTimeout)))
+}
+
+// queryProgress formats the result table status of a running query
+func (a *App) queryProgress(frame int, elapsed time.Duration) string {
+	status := fmt.Sprintf("%s running %s · C to cancel", spinnerFrames[frame%len(spinnerFrames)], formatElapsed(elapsed))
+	if len(a.queryResults) > 0 {
+		status = fmt.Sprintf("%d rows fetched · %s", len(a.queryResults), status)
+	}
+	return status
+}
+
+// queryResultStatus formats the fetched row count of the custom query for the result table title
+func (a *App) queryResultStatus() string {
+	status := fmt.Sprintf("%d rows fetched in %s", len(a.queryResults), formatElapsed(a.queryElapsed))
+	if a.queryMore {
+		status += " · more rows available, scroll down to load"
+	}
+	return status
+}
+
+// formatElapsed formats a query duration with a precision suited to its length
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatStatementTimeout formats the statement timeout, 0 meaning none
+func formatStatementTimeout(d time.Duration) string {
+	if d == 0 {
+		return "off"
+	}
+	return d.String()
+}
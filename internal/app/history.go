@@ -0,0 +1,187 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/config"
+	"p6s/internal/model"
+	"p6s/internal/ui"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// queryHistory returns the query history, oldest first, loading the history file on first use
+func (a *App) queryHistory() []model.HistoryEntry {
+	if !a.historyLoaded {
+		entries, err := config.LoadHistory()
+		if err != nil {
+			a.ShowError(fmt.Sprintf("Failed to load query history: %v", err))
+		}
+		a.history = entries
+		a.historyLoaded = true
+	}
+	return a.history
+}
+
+// recordHistory adds an executed statement to the query history, filling in the time and connection
+func (a *App) recordHistory(entry model.HistoryEntry, elapsed time.Duration, err error) {
+	entry.SQL = strings.TrimSpace(entry.SQL)
+	if entry.SQL == "" {
+		return
+	}
+	entry.Time = time.Now()
+	entry.Profile = fmt.Sprintf("%s@%s:%s", a.username, a.host, a.port)
+	entry.Database = a.database
+	entry.DurationMs = elapsed.Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	history := append(a.queryHistory(), entry)
+	if err := config.AppendHistory(entry); err != nil {
+		a.ShowError(fmt.Sprintf("Failed to save query history: %v", err))
+	}
+
+	// The file is only rewritten once it holds twice as many entries as are kept
+	if len(history) >= 2*config.MaxHistoryEntries {
+		history = append([]model.HistoryEntry(nil), history[len(history)-config.MaxHistoryEntries:]...)
+		if err := config.SaveHistory(history); err != nil {
+			a.ShowError(fmt.Sprintf("Failed to save query history: %v", err))
+		}
+	}
+	a.history = history
+}
+
+// showHistory shows the query history, newest first, with incremental search. Enter passes the
+// selected statement to load, Esc returns the focus to back.
+func (a *App) showHistory(search string, back tview.Primitive, load func(sqlQuery string)) {
+	history := a.queryHistory()
+	var matches []model.HistoryEntry
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	table.SetBorderColor(BorderColor)
+
+	preview := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	preview.SetBorder(true).SetTitle(" SQL ").SetTitleAlign(tview.AlignLeft)
+	preview.SetBorderColor(BorderColor)
+
+	input := tview.NewInputField().SetLabel("Search: ").SetFieldBackgroundColor(tcell.ColorBlack)
+	input.SetText(search)
+
+	filter := func(text string) {
+		words := strings.Fields(strings.ToLower(text))
+		matches = matches[:0]
+		for i := len(history) - 1; i >= 0; i-- {
+			if historyMatches(history[i], words) {
+				matches = append(matches, history[i])
+			}
+		}
+		ui.FillHistoryTable(table, matches)
+		table.SetTitle(fmt.Sprintf(" %d of %d Queries ", len(matches), len(history)))
+		if len(matches) == 0 {
+			preview.SetText("")
+			return
+		}
+		preview.SetText(ui.FormatHistoryEntry(matches[0])).ScrollToBeginning()
+	}
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if row >= 1 && row <= len(matches) {
+			preview.SetText(ui.FormatHistoryEntry(matches[row-1])).ScrollToBeginning()
+		}
+	})
+
+	selectEntry := func() {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(matches) {
+			return
+		}
+		sqlQuery := matches[row-1].SQL
+		a.closeHistory(back)
+		load(sqlQuery)
+	}
+	table.SetSelectedFunc(func(row, column int) {
+		selectEntry()
+	})
+
+	input.SetChangedFunc(filter)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			selectEntry()
+			return nil
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			// The selection moves while typing, like reverse search in a shell
+			table.InputHandler()(event, func(p tview.Primitive) {})
+			return nil
+		}
+		return event
+	})
+
+	browser := tview.NewFlex().SetDirection(tview.FlexRow)
+	browser.AddItem(input, 1, 0, true)
+	browser.AddItem(table, 0, 2, false)
+	browser.AddItem(preview, 0, 1, false)
+	browser.SetBorder(true).
+		SetTitle(" Query History (Esc Close  Enter Load  Ctrl-R Older  Tab Switch Pane) ").
+		SetTitleAlign(tview.AlignCenter)
+	browser.SetTitleColor(TitleColor)
+	browser.SetBorderColor(BorderColor)
+
+	browser.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.closeHistory(back)
+			return nil
+		case tcell.KeyCtrlR:
+			row, _ := table.GetSelection()
+			if row < len(matches) {
+				table.Select(row+1, 0)
+			}
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if input.HasFocus() {
+				a.ui.App.SetFocus(table)
+			} else {
+				a.ui.App.SetFocus(input)
+			}
+			return nil
+		}
+		return event
+	})
+
+	filter(search)
+
+	a.ui.Pages.RemovePage(HistoryPageName)
+	a.ui.Pages.AddPage(HistoryPageName, centered(browser, 0, 0), true, true)
+	a.ui.App.SetFocus(input)
+	a.refocusPage(HistoryPageName, input)
+}
+
+// closeHistory closes the query history page and focuses back
+func (a *App) closeHistory(back tview.Primitive) {
+	a.ui.Pages.RemovePage(HistoryPageName)
+	a.ui.App.SetFocus(back)
+}
+
+// historyMatches reports whether the statement, database or profile of the entry contain all words
+func historyMatches(entry model.HistoryEntry, words []string) bool {
+	text := strings.ToLower(entry.SQL + "\n" + entry.Database + "\n" + entry.Profile)
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleHistoryCommand handles "\history [search]", loading the selected statement into the SQL query window
+func (a *App) handleHistoryCommand(cmd string) {
+	search := strings.TrimSpace(strings.TrimPrefix(cmd, "\\history"))
+	a.showHistory(search, a.ui.ConnTable, func(sqlQuery string) {
+		a.showSQLQueryForm(sqlQuery)
+	})
+}
@@ -76,7 +76,8 @@ func (a *App) listNamespaces() {
 		return
 	}
 
-	// Clear table
+	// Clear table and leave refreshable views
+	a.filterType = "k8s"
 	a.ClearTable()
 
 	// Set table headers
@@ -99,7 +100,8 @@ func (a *App) listSecrets(namespace string) {
 		return
 	}
 
-	// Clear table
+	// Clear table and leave refreshable views
+	a.filterType = "k8s"
 	a.ClearTable()
 
 	// Set table headers
@@ -127,7 +129,8 @@ func (a *App) showSecret(namespace, name string) {
 		return
 	}
 
-	// Clear table
+	// Clear table and leave refreshable views
+	a.filterType = "k8s"
 	a.ClearTable()
 
 	// Set table headers
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Default thresholds of the long transaction view
+const (
+	DefaultIdleTxThreshold = time.Minute
+	DefaultLongTxThreshold = 5 * time.Minute
+)
+
+// longTxHeaders are the column headers of the long transaction view
+var longTxHeaders = []string{"PID", "User", "Database", "Application", "Status", "Xact Age", "State Age", "Backend XMIN", "Query"}
+
+// showLongTransactions switches to the long transaction view
+func (a *App) showLongTransactions() {
+	a.switchView("long_tx", longTxHeaders)
+}
+
+// handleLongTxCommand handles "\longtx [idle-seconds] [xact-seconds]"
+func (a *App) handleLongTxCommand(cmd string) {
+	parts := strings.Fields(cmd)
+
+	thresholds := []*time.Duration{&a.idleTxThreshold, &a.longTxThreshold}
+	for i, arg := range parts[1:] {
+		if i >= len(thresholds) {
+			break
+		}
+		seconds, err := strconv.ParseFloat(arg, 64)
+		if err != nil || seconds < 0 {
+			a.ShowError(fmt.Sprintf("Invalid threshold: %s, usage: \\longtx [idle-seconds] [xact-seconds]", arg))
+			return
+		}
+		*thresholds[i] = time.Duration(seconds * float64(time.Second))
+	}
+
+	a.showLongTransactions()
+}
+
+// confirmBulkTerminate shows the sessions matched by the long transaction view and terminates them on confirmation
+func (a *App) confirmBulkTerminate() {
+	idle, xact := a.idleTxThreshold, a.longTxThreshold
+
+	// Dry run: list exactly what the current thresholds match right now
+	connections, err := a.db.GetLongTransactions(idle, xact)
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+	if len(connections) == 0 {
+		a.ShowInfo("No sessions match the current thresholds")
+		return
+	}
+
+	var list strings.Builder
+	list.WriteString(fmt.Sprintf("[yellow]Idle in transaction > %s or transaction open > %s[white]\n\n", idle, xact))
+	pids := make([]int, len(connections))
+	for i, conn := range connections {
+		pids[i] = conn.PID
+		xactAge := ""
+		if conn.XactStart.Valid {
+			xactAge = time.Since(conn.XactStart.Time).Round(time.Second).String()
+		}
+		list.WriteString(fmt.Sprintf("%-8d %-16s %-16s %-30s %s\n",
+			conn.PID,
+			tview.Escape(formatNullString(conn.User)),
+			tview.Escape(formatNullString(conn.Database)),
+			tview.Escape(formatNullString(conn.State)),
+			xactAge))
+	}
+
+	listView := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	listView.SetText(list.String())
+
+	closeDialog := func() {
+		a.ui.Pages.RemovePage(BulkTerminatePageName)
+		a.ui.App.SetFocus(a.ui.ConnTable)
+	}
+
+	form := tview.NewForm()
+	form.AddButton(fmt.Sprintf("Terminate %d sessions", len(pids)), func() {
+		closeDialog()
+
+		terminated, err := a.db.TerminateLongTransactions(pids, idle, xact)
+		a.refreshData()
+		if err != nil {
+			a.ShowError(err.Error())
+			return
+		}
+		a.ShowInfo(fmt.Sprintf("Terminated %d of %d sessions\n(sessions that finished their transaction were skipped)", len(terminated), len(pids)))
+	})
+	form.AddButton("Back", closeDialog)
+	form.SetButtonsAlign(tview.AlignCenter)
+	form.GetButton(0).SetStyle(tcell.StyleDefault.Foreground(ButtonTextColor).Background(CancelButtonColor))
+	form.GetButton(1).SetStyle(tcell.StyleDefault.Foreground(ButtonTextColor).Background(SaveButtonColor))
+
+	dialog := tview.NewFlex().SetDirection(tview.FlexRow)
+	dialog.AddItem(listView, 0, 1, false)
+	dialog.AddItem(form, 3, 0, true)
+	dialog.SetBorder(true).SetTitle(" Dry Run: Terminate Matching Sessions ").SetTitleAlign(tview.AlignCenter)
+	dialog.SetTitleColor(TitleColor)
+	dialog.SetBorderColor(BorderColor)
+
+	dialog.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeDialog()
+			return nil
+		}
+		return event
+	})
+
+	a.ui.Pages.RemovePage(BulkTerminatePageName)
+	a.ui.Pages.AddPage(BulkTerminatePageName, centered(dialog, 100, 0), true, true)
+	a.ui.App.SetFocus(form)
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/db"
+	"p6s/internal/ui"
+	"sort"
+	"strings"
+)
+
+// metaCommandCompletions maps commands taking an object name to the kind of names completed for them
+var metaCommandCompletions = map[string]string{
+	"\\c":      db.CompleteDatabases,
+	"\\l":      db.CompleteDatabases,
+	"\\dn":     db.CompleteSchemas,
+	"\\du":     db.CompleteRoles,
+	"\\dt":     db.CompleteTables,
+	"\\tables": db.CompleteTables,
+	"\\di":     db.CompleteIndexes,
+	"\\dv":     db.CompleteViews,
+	"\\df":     db.CompleteFunctions,
+	"\\d":      db.CompleteRelations,
+}
+
+// commandNames lists the commands completed at the start of the command line
+var commandNames = []string{
+	"\\c", "\\config", "\\configk8s", "\\locks", "\\tables", "\\longtx", "\\timeout", "refresh",
+	"\\rw", "\\begin", "\\commit", "\\rollback", "\\onerror", "\\history",
+	"\\q", "\\qsave", "\\qdel",
+	"\\l", "\\dn", "\\dt", "\\di", "\\dv", "\\df", "\\du", "\\d", "\\x",
+}
+
+// isMetaCommand reports whether cmd is one of the psql describe commands
+func isMetaCommand(cmd string) bool {
+	parts := strings.Fields(cmd)
+	if len(parts) == 0 {
+		return false
+	}
+	switch parts[0] {
+	case "\\l", "\\dn", "\\dt", "\\di", "\\dv", "\\df", "\\du", "\\d", "\\x":
+		return true
+	}
+	return false
+}
+
+// handleMetaCommand runs a psql describe command and shows its result in the result table
+func (a *App) handleMetaCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	command, pattern := parts[0], ""
+	if len(parts) > 1 {
+		pattern = parts[1]
+	}
+
+	if command == "\\x" {
+		switch pattern {
+		case "on":
+			a.expandedDisplay = true
+		case "off":
+			a.expandedDisplay = false
+		case "":
+			a.expandedDisplay = !a.expandedDisplay
+		default:
+			a.ShowError(fmt.Sprintf("Invalid value: %s, usage: \\x [on|off]", pattern))
+			return
+		}
+		state := "off"
+		if a.expandedDisplay {
+			state = "on"
+		}
+		a.ShowInfo(fmt.Sprintf("Expanded display is %s.", state))
+		return
+	}
+
+	if a.db == nil || !a.db.IsConnected() {
+		a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
+		return
+	}
+	if a.queryBusy {
+		a.ShowError("A query is already running, press C in the result table to cancel it")
+		return
+	}
+	a.closeCustomQuery()
+
+	// \d with a pattern matching a single relation describes it, otherwise the matches are listed
+	if command == "\\d" && pattern != "" {
+		relations, err := a.db.FindRelations(pattern)
+		if err != nil {
+			a.ShowError(err.Error())
+			return
+		}
+		if len(relations) == 0 {
+			a.ShowError(fmt.Sprintf("Did not find any relation named \"%s\".", pattern))
+			return
+		}
+		if len(relations) == 1 {
+			detail, err := a.db.GetTableDetail(relations[0].Schema, relations[0].Name)
+			if err != nil {
+				a.ShowError(err.Error())
+				return
+			}
+			results, headers := ui.DescribeTableRows(detail)
+			a.showResults(results, headers)
+			return
+		}
+	}
+
+	results, headers, err := a.db.Describe(command, pattern)
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+	a.showResults(results, headers)
+}
+
+// showResults shows query results in the result table, in expanded display when \x is on
+func (a *App) showResults(results [][]interface{}, headers []string) {
+	a.resultRows = results
+	a.resultHeaders = headers
+	a.scriptStatement = -1
+	if a.expandedDisplay {
+		results, headers = ui.ExpandResults(results, headers)
+	}
+
+	a.filterType = "custom"
+	a.tableHeaders = headers
+	a.ui.TableHeaders = headers
+	a.ui.TableStatus = ""
+	a.ui.DisplayCustomQueryResults(results, headers)
+	a.ui.UpdateTableTitle()
+}
+
+// completeCommand completes the command or the object name under the cursor of the command line
+func (a *App) completeCommand() {
+	text := a.ui.CmdInput.GetText()
+
+	space := strings.LastIndex(text, " ")
+	prefix := text[space+1:]
+
+	// Nothing but blanks leaves no command to complete the argument of
+	fields := strings.Fields(text)
+	if space >= 0 && len(fields) == 0 {
+		return
+	}
+
+	var candidates []string
+	if space < 0 {
+		for _, name := range commandNames {
+			if strings.HasPrefix(name, prefix) {
+				candidates = append(candidates, name)
+			}
+		}
+	} else if command := fields[0]; command == "\\q" || command == "\\qdel" || command == "\\qsave" {
+		candidates = savedQueryNames(prefix)
+	} else {
+		kind, ok := metaCommandCompletions[fields[0]]
+		if !ok || a.db == nil || !a.db.IsConnected() {
+			return
+		}
+		names, err := a.db.GetCompletions(kind, prefix)
+		if err != nil {
+			a.ShowError(err.Error())
+			return
+		}
+		candidates = names
+	}
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	completed := candidates[0]
+	for _, candidate := range candidates[1:] {
+		completed = commonPrefix(completed, candidate)
+	}
+	if len(candidates) == 1 {
+		completed += " "
+	} else {
+		sort.Strings(candidates)
+		a.ShowInfo("Completions:\n" + strings.Join(candidates, "\n"))
+	}
+
+	a.ui.CmdInput.SetText(text[:space+1] + completed)
+}
+
+// commonPrefix returns the longest common prefix of a and b
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showResultRow shows all columns of the selected query result row with full, pretty-printed values
+func (a *App) showResultRow() {
+	row, _ := a.ui.ConnTable.GetSelection()
+
+	// In expanded display every record takes a separator row plus one row per column
+	record := row - 1
+	if a.expandedDisplay {
+		record = (row - 1) / (len(a.resultHeaders) + 1)
+	}
+	if record < 0 || record >= len(a.resultRows) {
+		return
+	}
+
+	detail := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	detail.SetText(ui.FormatResultRow(a.resultHeaders, a.resultRows[record]))
+	detail.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Row %d of %d (Esc Close) ", record+1, len(a.resultRows))).
+		SetTitleAlign(tview.AlignCenter)
+	detail.SetTitleColor(TitleColor)
+	detail.SetBorderColor(BorderColor)
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter {
+			a.closeResultRow()
+			return nil
+		}
+		return event
+	})
+
+	a.ui.Pages.RemovePage(ResultRowPageName)
+	a.ui.Pages.AddPage(ResultRowPageName, centered(detail, 0, 0), true, true)
+	a.ui.App.SetFocus(detail)
+}
+
+// closeResultRow closes the result row page
+func (a *App) closeResultRow() {
+	a.ui.Pages.RemovePage(ResultRowPageName)
+	a.ui.App.SetFocus(a.ui.ConnTable)
+}
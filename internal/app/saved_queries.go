@@ -0,0 +1,335 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/config"
+	"p6s/internal/db"
+	"p6s/internal/model"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// savedQueryHeaders are the columns of the saved query picker
+var savedQueryHeaders = []string{"Name", "Parameters", "Description"}
+
+// handleSavedQueryCommand handles "\q [name]", running the named saved query or opening the picker
+func (a *App) handleSavedQueryCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	if len(parts) < 2 {
+		a.showSavedQueries(a.ui.ConnTable)
+		return
+	}
+
+	query, err := config.LoadSavedQuery(parts[1])
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+	a.runSavedQuery(*query, a.ui.ConnTable)
+}
+
+// handleSaveQueryCommand handles "\qsave <name>", saving the last custom SQL under name
+func (a *App) handleSaveQueryCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	if len(parts) != 2 {
+		a.ShowError("Usage: \\qsave <name>")
+		return
+	}
+	if strings.TrimSpace(a.lastSQL) == "" {
+		a.ShowError("There is no custom SQL to save, run a query from the SQL query window first")
+		return
+	}
+
+	if err := config.SaveQuery(parts[1], a.lastSQL); err != nil {
+		a.ShowError(fmt.Sprintf("Failed to save query: %v", err))
+		return
+	}
+	a.ShowInfo(fmt.Sprintf("Saved the last custom SQL as %s, run it with \\q %s", parts[1], parts[1]))
+}
+
+// handleDeleteQueryCommand handles "\qdel <name>", deleting a saved query
+func (a *App) handleDeleteQueryCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	if len(parts) != 2 {
+		a.ShowError("Usage: \\qdel <name>")
+		return
+	}
+
+	if err := config.DeleteSavedQuery(parts[1]); err != nil {
+		a.ShowError(fmt.Sprintf("Failed to delete query: %v", err))
+		return
+	}
+	a.ShowInfo(fmt.Sprintf("Deleted saved query %s", parts[1]))
+}
+
+// runSavedQuery runs a saved query, asking for the values of its :name placeholders first.
+// Esc in the parameter form returns the focus to back.
+func (a *App) runSavedQuery(query model.SavedQuery, back tview.Primitive) {
+	a.lastSQL = query.SQL
+	statements := db.SplitStatements(query.SQL)
+
+	params := db.QueryParameters(query.SQL)
+	if len(params) == 0 {
+		a.executeStatements(statements)
+		return
+	}
+
+	a.showQueryParamsForm(query, params, back, func(values map[string]string) {
+		for i := range statements {
+			statement, err := db.BindParameters(statements[i], values)
+			if err != nil {
+				a.ShowError(err.Error())
+				return
+			}
+			statements[i] = statement
+		}
+		a.executeStatements(statements)
+	})
+}
+
+// showQueryParamsForm asks for the values of the placeholders of a saved query, prefilled with the
+// values entered last time
+func (a *App) showQueryParamsForm(query model.SavedQuery, params []string, back tview.Primitive, run func(values map[string]string)) {
+	if a.queryParamValues == nil {
+		a.queryParamValues = map[string]string{}
+	}
+
+	form := tview.NewForm()
+	for _, param := range params {
+		form.AddInputField(param, a.queryParamValues[param], 40, nil, nil)
+	}
+
+	// Values are sent as text parameters, the server infers their types from the statement
+	submit := func() {
+		values := map[string]string{}
+		for i, param := range params {
+			values[param] = form.GetFormItem(i).(*tview.InputField).GetText()
+			a.queryParamValues[param] = values[param]
+		}
+		a.ui.Pages.RemovePage(QueryParamsPageName)
+		a.ui.App.SetFocus(a.ui.ConnTable)
+		run(values)
+	}
+	cancel := func() {
+		a.ui.Pages.RemovePage(QueryParamsPageName)
+		a.ui.App.SetFocus(back)
+	}
+
+	form.AddButton("Run", submit)
+	form.AddButton("Cancel", cancel)
+
+	runButton := form.GetButton(0)
+	runButton.SetLabel("[::b]Run[::-]")
+	runButton.SetStyle(tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlue))
+	cancelButton := form.GetButton(1)
+	cancelButton.SetLabel("[::b]Cancel[::-]")
+	cancelButton.SetStyle(tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorRed))
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Run %s (Ctrl-S Run  Esc Cancel) ", query.Name)).
+		SetTitleAlign(tview.AlignCenter)
+	form.SetTitleColor(TitleColor)
+	form.SetBorderColor(BorderColor)
+	form.SetFieldTextColor(tcell.ColorWhite)
+	form.SetFieldBackgroundColor(tcell.ColorBlack)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			cancel()
+			return nil
+		case tcell.KeyCtrlS:
+			submit()
+			return nil
+		}
+		return event
+	})
+
+	height := len(params)*2 + 5
+	a.ui.Pages.RemovePage(QueryParamsPageName)
+	a.ui.Pages.AddPage(QueryParamsPageName, centered(form, 60, height), true, true)
+	a.ui.App.SetFocus(form)
+	a.refocusPage(QueryParamsPageName, form)
+}
+
+// showSavedQueries shows the saved queries with incremental search. Enter runs the selected query,
+// Ctrl-E opens it in the SQL query window and Esc returns the focus to back.
+func (a *App) showSavedQueries(back tview.Primitive) {
+	queries, err := config.LoadSavedQueries()
+	if err != nil {
+		a.ShowError(fmt.Sprintf("Failed to load saved queries: %v", err))
+		return
+	}
+	var matches []model.SavedQuery
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	table.SetBorderColor(BorderColor)
+
+	preview := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	preview.SetBorder(true).SetTitle(" SQL ").SetTitleAlign(tview.AlignLeft)
+	preview.SetBorderColor(BorderColor)
+
+	input := tview.NewInputField().SetLabel("Search: ").SetFieldBackgroundColor(tcell.ColorBlack)
+
+	filter := func(text string) {
+		words := strings.Fields(strings.ToLower(text))
+		matches = matches[:0]
+		for _, query := range queries {
+			if savedQueryMatches(query, words) {
+				matches = append(matches, query)
+			}
+		}
+		fillSavedQueryTable(table, matches)
+		table.SetTitle(fmt.Sprintf(" %d of %d Queries ", len(matches), len(queries)))
+		if len(matches) == 0 {
+			preview.SetText("")
+			return
+		}
+		preview.SetText(tview.Escape(matches[0].SQL)).ScrollToBeginning()
+	}
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if row >= 1 && row <= len(matches) {
+			preview.SetText(tview.Escape(matches[row-1].SQL)).ScrollToBeginning()
+		}
+	})
+
+	selected := func() (model.SavedQuery, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(matches) {
+			return model.SavedQuery{}, false
+		}
+		return matches[row-1], true
+	}
+	runSelected := func() {
+		if query, ok := selected(); ok {
+			a.ui.Pages.RemovePage(SavedQueriesPageName)
+			a.ui.App.SetFocus(back)
+			a.runSavedQuery(query, back)
+		}
+	}
+	table.SetSelectedFunc(func(row, column int) {
+		runSelected()
+	})
+
+	input.SetChangedFunc(filter)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			runSelected()
+			return nil
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			table.InputHandler()(event, func(p tview.Primitive) {})
+			return nil
+		}
+		return event
+	})
+
+	browser := tview.NewFlex().SetDirection(tview.FlexRow)
+	browser.AddItem(input, 1, 0, true)
+	browser.AddItem(table, 0, 2, false)
+	browser.AddItem(preview, 0, 1, false)
+	browser.SetBorder(true).
+		SetTitle(" Saved Queries (Esc Close  Enter Run  Ctrl-E Edit  Tab Switch Pane) ").
+		SetTitleAlign(tview.AlignCenter)
+	browser.SetTitleColor(TitleColor)
+	browser.SetBorderColor(BorderColor)
+
+	browser.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.ui.Pages.RemovePage(SavedQueriesPageName)
+			a.ui.App.SetFocus(back)
+			return nil
+		case tcell.KeyCtrlE:
+			if query, ok := selected(); ok {
+				a.ui.Pages.RemovePage(SavedQueriesPageName)
+				a.showSQLQueryForm(query.SQL)
+			}
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if input.HasFocus() {
+				a.ui.App.SetFocus(table)
+			} else {
+				a.ui.App.SetFocus(input)
+			}
+			return nil
+		}
+		return event
+	})
+
+	filter("")
+
+	a.ui.Pages.RemovePage(SavedQueriesPageName)
+	a.ui.Pages.AddPage(SavedQueriesPageName, centered(browser, 0, 0), true, true)
+	a.ui.App.SetFocus(input)
+	a.refocusPage(SavedQueriesPageName, input)
+}
+
+// refocusPage focuses p again once the command line focused the result table after its command ran
+func (a *App) refocusPage(pageName string, p tview.Primitive) {
+	go func() {
+		time.Sleep(UIUpdateDelay)
+		a.ui.App.QueueUpdateDraw(func() {
+			if frontPage, _ := a.ui.Pages.GetFrontPage(); frontPage == pageName {
+				a.ui.App.SetFocus(p)
+			}
+		})
+	}()
+}
+
+// fillSavedQueryTable fills the saved query picker with queries
+func fillSavedQueryTable(table *tview.Table, queries []model.SavedQuery) {
+	table.Clear()
+	table.SetFixed(1, 0)
+
+	for i, header := range savedQueryHeaders {
+		table.SetCell(0, i, tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+
+	if len(queries) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No saved queries, save the last custom SQL with \\qsave <name>").SetSelectable(false))
+		return
+	}
+
+	for i, query := range queries {
+		params := db.QueryParameters(query.SQL)
+		table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(query.Name)).SetTextColor(tcell.ColorAqua))
+		table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(strings.Join(params, ", "))))
+		table.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(query.Description)))
+	}
+	table.Select(1, 0)
+	table.ScrollToBeginning()
+}
+
+// savedQueryMatches reports whether the name, description or SQL of the query contain all words
+func savedQueryMatches(query model.SavedQuery, words []string) bool {
+	text := strings.ToLower(query.Name + "\n" + query.Description + "\n" + query.SQL)
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// savedQueryNames returns the names of the saved queries starting with prefix, for completion
+func savedQueryNames(prefix string) []string {
+	queries, err := config.LoadSavedQueries()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, query := range queries {
+		if strings.HasPrefix(query.Name, prefix) {
+			names = append(names, query.Name)
+		}
+	}
+	return names
+}
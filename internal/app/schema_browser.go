@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/model"
+	"p6s/internal/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showSchemaBrowser shows the schema tree of the current database with a detail pane and a fuzzy search box
+func (a *App) showSchemaBrowser() {
+	if a.db == nil || !a.db.IsConnected() {
+		a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
+		return
+	}
+
+	objects, err := a.db.GetSchemaObjects()
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+
+	root := ui.BuildSchemaTree(a.database, objects)
+
+	// Remember the parents of every object node to expand them when search jumps to the node
+	type searchEntry struct {
+		node    *tview.TreeNode
+		parents []*tview.TreeNode
+		text    string
+	}
+	var entries []searchEntry
+	parents := make(map[*tview.TreeNode]*tview.TreeNode)
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		parents[node] = parent
+		if object, ok := node.GetReference().(*model.SchemaObject); ok {
+			var chain []*tview.TreeNode
+			for p := parent; p != nil; p = parents[p] {
+				chain = append(chain, p)
+			}
+			entries = append(entries, searchEntry{node, chain, object.Schema + "." + ui.SchemaObjectLabel(object)})
+		}
+		return true
+	})
+
+	tree := tview.NewTreeView().SetRoot(root).SetTopLevel(1)
+	if children := root.GetChildren(); len(children) > 0 {
+		tree.SetCurrentNode(children[0])
+	}
+	tree.SetBorder(true).SetTitle(fmt.Sprintf(" %d Objects ", len(entries))).SetTitleAlign(tview.AlignLeft)
+	tree.SetBorderColor(BorderColor)
+
+	detail := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	detail.SetText("[::d]Select an object and press Enter to show its details[-:-:-]")
+	detail.SetBorder(true).SetTitle(" Detail ").SetTitleAlign(tview.AlignLeft)
+	detail.SetBorderColor(BorderColor)
+
+	search := tview.NewInputField().SetLabel("Search: ").SetFieldBackgroundColor(tcell.ColorBlack)
+
+	showObject := func(object *model.SchemaObject) {
+		text, err := a.formatSchemaObject(object)
+		if err != nil {
+			text = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+		}
+		detail.SetText(text).ScrollToBeginning()
+		detail.SetTitle(fmt.Sprintf(" %s %s.%s ", object.Kind, tview.Escape(object.Schema), tview.Escape(ui.SchemaObjectLabel(object))))
+	}
+
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if object, ok := node.GetReference().(*model.SchemaObject); ok {
+			showObject(object)
+			return
+		}
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	search.SetChangedFunc(func(text string) {
+		best, bestScore := -1, 0
+		for i, entry := range entries {
+			if score, ok := ui.FuzzyScore(text, entry.text); ok && (best < 0 || score > bestScore) {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			search.SetLabel("Search (no match): ")
+			return
+		}
+		search.SetLabel("Search: ")
+		for _, parent := range entries[best].parents {
+			parent.SetExpanded(true)
+		}
+		tree.SetCurrentNode(entries[best].node)
+	})
+
+	search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			a.ui.App.SetFocus(tree)
+			if node := tree.GetCurrentNode(); node != nil {
+				if object, ok := node.GetReference().(*model.SchemaObject); ok {
+					showObject(object)
+				}
+			}
+		}
+	})
+
+	panes := tview.NewFlex().SetDirection(tview.FlexColumn)
+	panes.AddItem(tree, 0, 1, false)
+	panes.AddItem(detail, 0, 2, false)
+
+	browser := tview.NewFlex().SetDirection(tview.FlexRow)
+	browser.AddItem(search, 1, 0, false)
+	browser.AddItem(panes, 0, 1, true)
+	browser.SetBorder(true).
+		SetTitle(" Schema Browser (Esc Close  Tab Switch Pane  / Search) ").
+		SetTitleAlign(tview.AlignCenter)
+	browser.SetTitleColor(TitleColor)
+	browser.SetBorderColor(BorderColor)
+
+	focusOrder := []tview.Primitive{search, tree, detail}
+	browser.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.closeSchemaBrowser()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			step := 1
+			if event.Key() == tcell.KeyBacktab {
+				step = len(focusOrder) - 1
+			}
+			for i, primitive := range focusOrder {
+				if primitive.HasFocus() {
+					a.ui.App.SetFocus(focusOrder[(i+step)%len(focusOrder)])
+					return nil
+				}
+			}
+			a.ui.App.SetFocus(tree)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == '/' && !search.HasFocus() {
+				a.ui.App.SetFocus(search)
+				return nil
+			}
+		}
+		return event
+	})
+
+	a.ui.Pages.RemovePage(SchemaBrowserPageName)
+	a.ui.Pages.AddPage(SchemaBrowserPageName, centered(browser, 0, 0), true, true)
+	a.ui.App.SetFocus(tree)
+}
+
+// formatSchemaObject loads and formats the detail pane text of a schema object
+func (a *App) formatSchemaObject(object *model.SchemaObject) (string, error) {
+	switch object.Kind {
+	case model.ObjectFunction:
+		detail, err := a.db.GetFunctionDetail(object.OID)
+		if err != nil {
+			return "", err
+		}
+		return ui.FormatFunctionDetail(detail), nil
+	case model.ObjectSequence:
+		detail, err := a.db.GetSequenceDetail(object.Schema, object.Name)
+		if err != nil {
+			return "", err
+		}
+		return ui.FormatSequenceDetail(detail), nil
+	default:
+		detail, err := a.db.GetTableDetail(object.Schema, object.Name)
+		if err != nil {
+			return "", err
+		}
+		return ui.FormatTableDetail(detail), nil
+	}
+}
+
+// closeSchemaBrowser closes the schema browser page
+func (a *App) closeSchemaBrowser() {
+	a.ui.Pages.RemovePage(SchemaBrowserPageName)
+	a.ui.App.SetFocus(a.ui.ConnTable)
+}
@@ -0,0 +1,375 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"p6s/internal/db"
+	"p6s/internal/model"
+	"strings"
+	"time"
+)
+
+// scriptAbortTimeout bounds the wait for the running statement when a script is aborted
+const scriptAbortTimeout = 5 * time.Second
+
+// scriptHeaders are the columns of the script result list
+var scriptHeaders = []string{"#", "Line", "Status", "Rows", "Duration", "Statement", "Message"}
+
+// scriptRun is a custom SQL script whose statements run one after the other, each as its own
+// custom query on the one connection the script reserves. Only the first page of rows of every
+// statement is kept.
+type scriptRun struct {
+	statements      []db.Statement
+	results         []model.StatementResult
+	next            int
+	continueOnError bool
+	started         time.Time
+	elapsed         time.Duration
+	progressDone    chan struct{}
+
+	// query is the running statement, nil between statements. statementDone is closed once its
+	// goroutine no longer uses the connection of the script.
+	query         *db.CustomQuery
+	statementDone chan struct{}
+	cancelled     bool
+	done          bool
+	// database holds the session of the script, released once no statement uses it
+	database *db.PostgresDB
+}
+
+// runScript runs the statements of a script in order, showing their progress in the script result list
+func (a *App) runScript(statements []db.Statement) {
+	// Inside a transaction the script reuses its connection, the open result cursor must be closed
+	// on it before the first statement runs
+	if query := a.customQuery; query != nil {
+		a.customQuery = nil
+		a.queryMore = false
+		query.Close()
+	}
+	// The statements share SET, temporary tables and the like like in a psql session
+	if err := a.db.BeginSession(); err != nil {
+		a.showQueryError(err.Error())
+		return
+	}
+	a.queryResults = nil
+	a.queryHeaders = nil
+	a.resultRows = nil
+
+	script := &scriptRun{
+		statements:      statements,
+		continueOnError: a.scriptContinueOnError,
+		started:         time.Now(),
+		progressDone:    make(chan struct{}),
+		database:        a.db,
+	}
+	for _, statement := range statements {
+		script.results = append(script.results, model.StatementResult{
+			Line:   statement.Line,
+			SQL:    statement.Source(),
+			Status: model.StatementPending,
+		})
+	}
+	a.script = script
+	a.queryBusy = true
+	a.showScriptResults()
+
+	go a.progressLoop(script.started, script.progressDone, func(frame int, elapsed time.Duration) {
+		if a.script != script || script.done || a.filterType != "script" {
+			return
+		}
+		a.ui.TableStatus = a.scriptProgress(frame, elapsed)
+		a.ui.UpdateTableTitle()
+	})
+
+	a.runNextStatement(script)
+}
+
+// runNextStatement starts the next statement of the script in the background. Transaction
+// statements run right away, they decide which connection the following statements use.
+func (a *App) runNextStatement(script *scriptRun) {
+	if script.next >= len(script.statements) {
+		a.finishScript(script)
+		return
+	}
+	index := script.next
+	statement := script.statements[index]
+	result := &script.results[index]
+
+	if command, text, ok := transactionStatement(statement.Text); ok && len(statement.Args) == 0 {
+		started := time.Now()
+		message, err := a.runTransactionCommand(command, text)
+		result.Duration = time.Since(started)
+		if err == nil {
+			result.Message = message
+		}
+		a.completeStatement(script, index, err)
+		return
+	}
+
+	result.Status = model.StatementRunning
+	a.refreshScriptResults(script)
+
+	query := a.db.NewCustomQuery(a.statementTimeout)
+	statementDone := make(chan struct{})
+	script.query = query
+	script.statementDone = statementDone
+
+	go func() {
+		started := time.Now()
+		results, headers, more, err := query.Run(statement.Text, QueryPageSize, statement.Args...)
+		// The rows after the first page are not read
+		query.Close()
+		close(statementDone)
+		elapsed := time.Since(started)
+
+		a.ui.App.QueueUpdateDraw(func() {
+			if a.script != script || script.done {
+				return
+			}
+			script.query = nil
+
+			result.Duration = elapsed
+			if err == nil {
+				result.Headers = headers
+				result.Results = results
+				result.Rows = int64(len(results))
+				result.MoreRows = more
+				if affected, ok := query.RowsAffected(); ok {
+					result.Rows = affected
+					result.Affected = true
+				}
+			}
+			a.completeStatement(script, index, err)
+		})
+	}()
+}
+
+// completeStatement records the outcome of a statement and goes on with the next one, unless the
+// script was cancelled or stops on errors
+func (a *App) completeStatement(script *scriptRun, index int, err error) {
+	result := &script.results[index]
+	switch {
+	case errors.Is(err, db.ErrQueryCancelled):
+		result.Status = model.StatementCancelled
+		result.Message = "Query cancelled"
+	case err != nil:
+		result.Status = model.StatementFailed
+		result.Message = err.Error()
+	default:
+		result.Status = model.StatementOK
+	}
+	a.recordHistory(model.HistoryEntry{
+		SQL:      result.SQL,
+		Params:   script.statements[index].Params,
+		Rows:     result.Rows,
+		MoreRows: result.MoreRows,
+		Affected: result.Affected,
+	}, result.Duration, err)
+	// A failing statement aborts the open transaction
+	a.updateConnectionMode()
+
+	script.next = index + 1
+	if script.cancelled || err != nil && !script.continueOnError {
+		a.finishScript(script)
+		return
+	}
+	a.runNextStatement(script)
+}
+
+// finishScript ends the script, the statements that did not run are skipped
+func (a *App) finishScript(script *scriptRun) {
+	script.done = true
+	script.query = nil
+	script.elapsed = time.Since(script.started)
+	close(script.progressDone)
+	a.queryBusy = false
+	script.database.EndSession()
+
+	for i := range script.results {
+		switch script.results[i].Status {
+		case model.StatementPending:
+			script.results[i].Status = model.StatementSkipped
+		case model.StatementRunning:
+			script.results[i].Status = model.StatementCancelled
+		}
+	}
+
+	a.refreshScriptResults(script)
+	if a.filterType == "script" {
+		a.ui.TableStatus = a.scriptStatus()
+		a.ui.UpdateTableTitle()
+	}
+}
+
+// cancelScript cancels the running statement and skips the rest of the script
+func (a *App) cancelScript() {
+	script := a.script
+	if script == nil || script.done {
+		return
+	}
+	script.cancelled = true
+
+	if a.filterType == "script" {
+		a.ui.TableStatus = "cancelling..."
+		a.ui.UpdateTableTitle()
+	}
+
+	query := script.query
+	if query == nil {
+		return
+	}
+	go func() {
+		if err := query.Cancel(); err != nil {
+			a.ui.App.QueueUpdateDraw(func() {
+				a.ShowError(err.Error())
+			})
+		}
+	}()
+}
+
+// abortScript stops the running script at once, e.g. before its connection is closed. The running
+// statement is aborted and waited for, its connection must not be reset or released while in use.
+func (a *App) abortScript() {
+	script := a.script
+	if script == nil || script.done {
+		return
+	}
+	script.cancelled = true
+
+	if query := script.query; query != nil {
+		query.Abort()
+		select {
+		case <-script.statementDone:
+		case <-time.After(scriptAbortTimeout):
+		}
+	}
+	a.finishScript(script)
+}
+
+// showScriptResults shows the statements of the last script, selecting the statement whose result was viewed
+func (a *App) showScriptResults() {
+	selected := a.scriptStatement
+	a.scriptStatement = -1
+
+	a.filterType = "script"
+	a.tableHeaders = scriptHeaders
+	a.ui.TableHeaders = scriptHeaders
+	a.ui.TableStatus = a.scriptStatus()
+
+	var results []model.StatementResult
+	if a.script != nil {
+		results = a.script.results
+	}
+	a.ui.DisplayScriptResults(results)
+	if selected >= 0 && selected < len(results) {
+		a.ui.ConnTable.Select(selected+1, 0)
+	}
+	a.ui.UpdateTableTitle()
+}
+
+// refreshScriptResults redraws the script result list, keeping the selection
+func (a *App) refreshScriptResults(script *scriptRun) {
+	if a.script != script || a.filterType != "script" {
+		return
+	}
+	row, column := a.ui.ConnTable.GetSelection()
+	rowOffset, columnOffset := a.ui.ConnTable.GetOffset()
+
+	a.ui.DisplayScriptResults(script.results)
+
+	a.ui.ConnTable.Select(row, column)
+	a.ui.ConnTable.SetOffset(rowOffset, columnOffset)
+}
+
+// showScriptStatement shows the result grid of the selected script statement, Esc returns to the list
+func (a *App) showScriptStatement() {
+	if a.script == nil {
+		return
+	}
+	row, _ := a.ui.ConnTable.GetSelection()
+	index := row - 1
+	if index < 0 || index >= len(a.script.results) {
+		return
+	}
+	result := a.script.results[index]
+
+	switch {
+	case result.Status == model.StatementFailed || result.Status == model.StatementCancelled:
+		a.showResults([][]interface{}{{result.Message}}, []string{"Error"})
+	case result.Affected:
+		a.showResults([][]interface{}{{fmt.Sprintf("%d rows affected", result.Rows)}}, []string{"Result"})
+	case result.Headers == nil && result.Message != "":
+		a.showResults([][]interface{}{{result.Message}}, []string{"Result"})
+	case result.Status == model.StatementOK:
+		a.showResults(result.Results, result.Headers)
+	default:
+		a.ShowInfo(fmt.Sprintf("Statement %d has no result (%s)", index+1, result.Status))
+		return
+	}
+
+	a.scriptStatement = index
+	status := fmt.Sprintf("statement %d of %d", index+1, len(a.script.results))
+	if result.MoreRows {
+		status += fmt.Sprintf(" · first %d rows", result.Rows)
+	}
+	a.ui.TableStatus = status + " · Esc back to the script results"
+	a.ui.UpdateTableTitle()
+}
+
+// scriptProgress formats the result table status of the running script
+func (a *App) scriptProgress(frame int, elapsed time.Duration) string {
+	script := a.script
+	return fmt.Sprintf("%s statement %d of %d running %s · C to cancel",
+		spinnerFrames[frame%len(spinnerFrames)], script.next+1, len(script.statements), formatElapsed(elapsed))
+}
+
+// scriptStatus summarizes the statuses of the statements of the finished script
+func (a *App) scriptStatus() string {
+	script := a.script
+	if script == nil {
+		return ""
+	}
+	if !script.done {
+		return a.scriptProgress(0, time.Since(script.started))
+	}
+
+	counts := map[string]int{}
+	for _, result := range script.results {
+		counts[result.Status]++
+	}
+	var parts []string
+	for _, status := range []string{model.StatementOK, model.StatementFailed, model.StatementCancelled, model.StatementSkipped} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+
+	mode := "stop on error"
+	if script.continueOnError {
+		mode = "continue on error"
+	}
+	return fmt.Sprintf("%d statements in %s · %s · %s · Enter to view a result",
+		len(script.results), formatElapsed(script.elapsed), strings.Join(parts, ", "), mode)
+}
+
+// handleOnErrorCommand handles "\onerror [stop|continue]", choosing whether scripts go on after a failed statement
+func (a *App) handleOnErrorCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	if len(parts) > 1 {
+		switch parts[1] {
+		case "stop":
+			a.scriptContinueOnError = false
+		case "continue":
+			a.scriptContinueOnError = true
+		default:
+			a.ShowError(fmt.Sprintf("Invalid value: %s, usage: \\onerror [stop|continue]", parts[1]))
+			return
+		}
+	}
+
+	if a.scriptContinueOnError {
+		a.ShowInfo("Scripts continue with the next statement when a statement fails")
+		return
+	}
+	a.ShowInfo("Scripts stop at the first failing statement")
+}
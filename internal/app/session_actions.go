@@ -0,0 +1,113 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"p6s/internal/model"
+
+	"github.com/rivo/tview"
+)
+
+// selectedConnection returns the session of the selected row in connection and lock tree views
+func (a *App) selectedConnection() (*model.Connection, bool) {
+	row, _ := a.ui.ConnTable.GetSelection()
+
+	switch a.filterType {
+	case "all", "active", "blocked", "long_tx":
+		if row < 1 || row > len(a.connections) {
+			return nil, false
+		}
+		return &a.connections[row-1], true
+	case "lock_tree":
+		if row < 1 || row > len(a.lockTree) {
+			return nil, false
+		}
+		node := a.lockTree[row-1]
+		return &model.Connection{
+			PID:      node.PID,
+			User:     node.User,
+			Database: node.Database,
+			State:    node.State,
+			Query:    node.Query,
+		}, true
+	}
+
+	return nil, false
+}
+
+// confirmBackendAction asks for confirmation before cancelling or terminating the selected backend
+func (a *App) confirmBackendAction(terminate bool) {
+	conn, ok := a.selectedConnection()
+	if !ok {
+		a.ShowError("Please select a session row in a connection or lock tree view first")
+		return
+	}
+
+	action := "Cancel"
+	if terminate {
+		action = "Terminate"
+	}
+
+	pid := conn.PID
+	text := fmt.Sprintf(
+		"%s backend PID %d?\n\n"+
+			"User: %s\n"+
+			"Database: %s\n"+
+			"Status: %s\n\n"+
+			"Query:\n%s",
+		action, pid,
+		tview.Escape(formatNullString(conn.User)),
+		tview.Escape(formatNullString(conn.Database)),
+		tview.Escape(formatNullString(conn.State)),
+		tview.Escape(formatNullString(conn.Query)))
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{action, "Back"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.ui.Pages.RemovePage(ConfirmActionPageName)
+			a.ui.App.SetFocus(a.ui.ConnTable)
+
+			if buttonLabel == action {
+				a.runBackendAction(pid, terminate)
+			}
+		})
+	modal.SetTitle(fmt.Sprintf(" %s Backend ", action)).SetBorderColor(BorderColor)
+
+	a.ui.Pages.RemovePage(ConfirmActionPageName)
+	a.ui.Pages.AddPage(ConfirmActionPageName, modal, true, true)
+	a.ui.App.SetFocus(modal)
+}
+
+// runBackendAction cancels or terminates the backend and refreshes the current view
+func (a *App) runBackendAction(pid int, terminate bool) {
+	var signalled bool
+	var err error
+	if terminate {
+		signalled, err = a.db.TerminateBackend(pid)
+	} else {
+		signalled, err = a.db.CancelBackend(pid)
+	}
+
+	// refreshData reports its own failures in the info panel
+	a.refreshData()
+
+	switch {
+	case err != nil:
+		a.ShowError(err.Error())
+	case !signalled:
+		a.ShowError(fmt.Sprintf("Backend %d could not be signalled (already gone or insufficient privileges)", pid))
+	case terminate:
+		a.ShowInfo(fmt.Sprintf("Backend %d terminated", pid))
+	default:
+		a.ShowInfo(fmt.Sprintf("Query of backend %d cancelled", pid))
+	}
+}
+
+// formatNullString returns the string value or empty string for NULL
+func formatNullString(s sql.NullString) string {
+	if s.Valid {
+		return s.String
+	}
+	return ""
+}
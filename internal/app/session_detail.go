@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showSessionDetail shows activity, locks and blockers of the selected session
+func (a *App) showSessionDetail() {
+	conn, ok := a.selectedConnection()
+	if !ok {
+		return
+	}
+
+	session, err := a.db.GetSession(conn.PID)
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+
+	locks, err := a.db.GetLocks(session.PID)
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+
+	blockers, err := a.db.GetBlockingPIDs(session.PID)
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+
+	detail := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	detail.SetText(ui.FormatSessionDetail(session, locks, blockers))
+	detail.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Session %d (Esc Close  C/K Cancel/Terminate) ", session.PID)).
+		SetTitleAlign(tview.AlignCenter)
+	detail.SetTitleColor(TitleColor)
+	detail.SetBorderColor(BorderColor)
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter:
+			a.closeSessionDetail()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'C':
+			a.closeSessionDetail()
+			a.confirmBackendAction(false)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'K':
+			a.closeSessionDetail()
+			a.confirmBackendAction(true)
+			return nil
+		}
+		return event
+	})
+
+	a.ui.Pages.RemovePage(SessionDetailPageName)
+	a.ui.Pages.AddPage(SessionDetailPageName, centered(detail, 0, 0), true, true)
+	a.ui.App.SetFocus(detail)
+}
+
+// closeSessionDetail closes the session detail page
+func (a *App) closeSessionDetail() {
+	a.ui.Pages.RemovePage(SessionDetailPageName)
+	a.ui.App.SetFocus(a.ui.ConnTable)
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/db"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// guardPreviewLength is how much of a guarded statement the confirmation shows
+const guardPreviewLength = 200
+
+// guardedStatement is a statement of custom SQL that needs confirmation before it runs
+type guardedStatement struct {
+	statement db.Statement
+	class     db.StatementClass
+}
+
+// SetGuards sets the guards of the connection profile, kinds set to false are not confirmed
+func (a *App) SetGuards(guards map[string]bool) {
+	a.guards = guards
+}
+
+// guardEnabled reports whether statements of the guard kind need confirmation, all kinds do by default
+func (a *App) guardEnabled(guard string) bool {
+	enabled, ok := a.guards[guard]
+	return !ok || enabled
+}
+
+// guardedStatements returns the statements that need confirmation. Read-only sessions are asked too,
+// the statements may run after the read-only mode was lifted.
+func (a *App) guardedStatements(statements []db.Statement) []guardedStatement {
+	var guarded []guardedStatement
+	for _, statement := range statements {
+		class := db.ClassifyStatement(statement.Text)
+		if class.Guard != "" && a.guardEnabled(class.Guard) {
+			guarded = append(guarded, guardedStatement{statement: statement, class: class})
+		}
+	}
+	return guarded
+}
+
+// confirmGuardedSQL shows the type and estimated affected rows of the guarded statements and runs
+// the statements once confirmed. The estimates are made in the background, EXPLAIN may wait for locks.
+func (a *App) confirmGuardedSQL(statements []db.Statement, guarded []guardedStatement) {
+	estimator := a.db.NewRowEstimator()
+	estimates := make([]string, len(guarded))
+	for i := range estimates {
+		estimates[i] = "estimating rows..."
+	}
+
+	modal := tview.NewModal().
+		SetText(guardedSQLText(guarded, estimates)).
+		AddButtons([]string{"Execute", "Back"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			// The estimates may run on the connection of the transaction the statements run on
+			estimator.Stop()
+			a.ui.Pages.RemovePage(ConfirmSQLPageName)
+			a.ui.App.SetFocus(a.ui.ConnTable)
+
+			if buttonLabel == "Execute" {
+				a.runStatements(statements)
+			}
+		})
+	modal.SetTitle(" Confirm SQL ").SetBorderColor(BorderColor)
+
+	a.ui.Pages.RemovePage(ConfirmSQLPageName)
+	a.ui.Pages.AddPage(ConfirmSQLPageName, modal, true, true)
+	a.ui.App.SetFocus(modal)
+
+	go func() {
+		done := make([]string, len(guarded))
+		for i, g := range guarded {
+			done[i] = estimatedRowsText(estimator, g)
+		}
+		a.ui.App.QueueUpdateDraw(func() {
+			// The confirmation may have been answered meanwhile
+			if _, page := a.ui.Pages.GetFrontPage(); page == modal {
+				modal.SetText(guardedSQLText(guarded, done))
+			}
+		})
+	}()
+}
+
+// guardedSQLText describes the guarded statements with their estimated rows for the confirmation
+func guardedSQLText(guarded []guardedStatement, estimates []string) string {
+	var sb strings.Builder
+	sb.WriteString("This SQL changes the schema or may affect many rows:\n")
+	for i, g := range guarded {
+		sb.WriteString(fmt.Sprintf("\n[yellow::b]%s[-:-:-] · %s\n%s\n",
+			tview.Escape(g.class.Type),
+			estimates[i],
+			tview.Escape(statementPreview(g.statement.Text))))
+	}
+	return sb.String()
+}
+
+// estimatedRowsText describes the estimated rows of a guarded statement for the confirmation
+func estimatedRowsText(estimator *db.RowEstimator, g guardedStatement) string {
+	rows, ok, err := estimator.EstimateAffectedRows(g.statement.Text, g.class, g.statement.Args...)
+	switch {
+	case err != nil:
+		return "estimated rows unknown (" + tview.Escape(err.Error()) + ")"
+	case !ok:
+		return "estimated rows unknown"
+	case rows == 1:
+		return "~1 row (estimated)"
+	}
+	return fmt.Sprintf("~%d rows (estimated)", rows)
+}
+
+// readOnlyViolation returns the first statement that would lift the read-only mode of the session,
+// which only \rw may do
+func (a *App) readOnlyViolation(statements []db.Statement) (db.Statement, bool) {
+	if a.readWrite {
+		return db.Statement{}, false
+	}
+	for _, statement := range statements {
+		if db.EnablesWrites(statement.Text) {
+			return statement, true
+		}
+	}
+	return db.Statement{}, false
+}
+
+// statementPreview shortens a statement to a single line for the confirmation
+func statementPreview(statement string) string {
+	preview := []rune(strings.Join(strings.Fields(statement), " "))
+	if len(preview) > guardPreviewLength {
+		return string(preview[:guardPreviewLength]) + "..."
+	}
+	return string(preview)
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showTableDetail shows structure, activity and DDL of the table selected in the table statistics view
+func (a *App) showTableDetail() {
+	row, _ := a.ui.ConnTable.GetSelection()
+	if row < 1 || row > len(a.tableStats) {
+		return
+	}
+	stat := a.tableStats[row-1]
+
+	detail, err := a.db.GetTableDetail(stat.Schema, stat.Name)
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+
+	view := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(true)
+	view.SetText(ui.FormatTableDetail(detail))
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s.%s (Esc Close  B Bloat) ", tview.Escape(detail.Schema), tview.Escape(detail.Name))).
+		SetTitleAlign(tview.AlignCenter)
+	view.SetTitleColor(TitleColor)
+	view.SetBorderColor(BorderColor)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter:
+			a.closeTableDetail()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'B':
+			a.closeTableDetail()
+			a.showBloat(detail.Schema, detail.Name)
+			return nil
+		}
+		return event
+	})
+
+	a.ui.Pages.RemovePage(TableDetailPageName)
+	a.ui.Pages.AddPage(TableDetailPageName, centered(view, 0, 0), true, true)
+	a.ui.App.SetFocus(view)
+}
+
+// closeTableDetail closes the table detail page
+func (a *App) closeTableDetail() {
+	a.ui.Pages.RemovePage(TableDetailPageName)
+	a.ui.App.SetFocus(a.ui.ConnTable)
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/db"
+	"strings"
+)
+
+// tableStatHeaders are the column headers of the table statistics view
+var tableStatHeaders = []string{"Schema", "Table Name", "Type", "Total Size", "Table Size", "Index Size", "Total Rows"}
+
+// tableStatSorts lists sortable columns of the table statistics view in switching order,
+// with the direction used when the column is first selected
+var tableStatSorts = []struct {
+	column     string
+	header     int
+	descending bool
+}{
+	{db.TableSortTotalSize, 3, true},
+	{db.TableSortTableSize, 4, true},
+	{db.TableSortIndexSize, 5, true},
+	{db.TableSortRows, 6, true},
+	{db.TableSortSchema, 0, false},
+	{db.TableSortName, 1, false},
+	{db.TableSortKind, 2, false},
+}
+
+// showTableStats switches to the table statistics view
+func (a *App) showTableStats() {
+	a.switchView("table_size", markSortDirection(tableStatHeaders, tableStatSorts[a.tableSort].header, a.tableSortDesc))
+}
+
+// tableStatsFilter returns the filter of the table statistics view
+func (q viewQuery) tableStatsFilter() db.TableStatsFilter {
+	return db.TableStatsFilter{
+		Schema:     q.tableSchemaFilter,
+		Name:       q.tableNameFilter,
+		SortBy:     tableStatSorts[q.tableSort].column,
+		Descending: q.tableSortDesc,
+		Page:       q.tablePage,
+	}
+}
+
+// nextTableSort switches the table statistics view to the next sort column
+func (a *App) nextTableSort() {
+	a.setTableSort((a.tableSort + 1) % len(tableStatSorts))
+}
+
+// setTableSort sorts the table statistics view by the given sort, reversing the direction when already sorted by it
+func (a *App) setTableSort(sort int) {
+	if sort == a.tableSort {
+		a.tableSortDesc = !a.tableSortDesc
+	} else {
+		a.tableSort = sort
+		a.tableSortDesc = tableStatSorts[sort].descending
+	}
+	a.tablePage = 0
+	a.showTableStats()
+}
+
+// sortByHeader sorts the current view by the clicked header column when the view supports it
+func (a *App) sortByHeader(column int) {
+	switch a.filterType {
+	case "table_size":
+		for i, sort := range tableStatSorts {
+			if sort.header == column {
+				a.setTableSort(i)
+				return
+			}
+		}
+	case "top_queries":
+		for i, sort := range topQuerySorts {
+			if sort.header == column {
+				a.topQuerySort = i
+				a.showTopQueries()
+				return
+			}
+		}
+	case "bloat":
+		for i, sort := range bloatSorts {
+			if sort.header == column {
+				a.bloatSort = i
+				a.showBloat(a.bloatSchema, a.bloatTable)
+				return
+			}
+		}
+	}
+}
+
+// changeTablePage moves the table statistics view by delta pages
+func (a *App) changeTablePage(delta int) {
+	page := a.tablePage + delta
+	pages := (a.tableStatTotal + db.TableStatsPageSize - 1) / db.TableStatsPageSize
+	if page < 0 || page >= pages {
+		return
+	}
+	a.tablePage = page
+	a.showTableStats()
+}
+
+// tableStatsStatus describes the shown page and filter of the table statistics view
+func (a *App) tableStatsStatus() string {
+	pages := (a.tableStatTotal + db.TableStatsPageSize - 1) / db.TableStatsPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	status := fmt.Sprintf("page %d/%d, %d relations", a.tablePage+1, pages, a.tableStatTotal)
+	if a.tableSchemaFilter != "" || a.tableNameFilter != "" {
+		status += fmt.Sprintf(", filter schema %q name %q", a.tableSchemaFilter, a.tableNameFilter)
+	}
+	return status
+}
+
+// handleTablesCommand handles "\tables [[schema.]name]", filtering the table statistics view.
+// Patterns accept * and ? wildcards, a name without wildcards matches as substring.
+func (a *App) handleTablesCommand(cmd string) {
+	parts := strings.Fields(cmd)
+
+	a.tableSchemaFilter, a.tableNameFilter = "", ""
+	if len(parts) > 1 {
+		pattern := parts[1]
+		if dot := strings.Index(pattern, "."); dot >= 0 {
+			a.tableSchemaFilter = likePattern(pattern[:dot], false)
+			pattern = pattern[dot+1:]
+		}
+		a.tableNameFilter = likePattern(pattern, true)
+	}
+
+	a.tablePage = 0
+	a.showTableStats()
+}
+
+// likePattern converts a pattern with * and ? wildcards to an ILIKE pattern,
+// patterns without wildcards match as substring when substring is set
+func likePattern(pattern string, substring bool) string {
+	if pattern == "" || pattern == "*" {
+		return ""
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(pattern)
+	if !strings.ContainsAny(pattern, "*?") {
+		if substring {
+			return "%" + escaped + "%"
+		}
+		return escaped
+	}
+	return strings.NewReplacer("*", "%", "?", "_").Replace(escaped)
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"p6s/internal/db"
+)
+
+// topQueryHeaders are the column headers of the top queries view
+var topQueryHeaders = []string{"Calls", "Total Time", "Mean Time", "Rows", "Shared Hit", "Shared Read", "Hit %", "Temp Blocks", "User", "Query"}
+
+// topQuerySorts lists sortable columns of the top queries view in switching order
+var topQuerySorts = []struct {
+	column string
+	header int
+}{
+	{db.SortByTotalTime, 1},
+	{db.SortByMeanTime, 2},
+	{db.SortByCalls, 0},
+	{db.SortByRows, 3},
+	{db.SortBySharedRead, 5},
+	{db.SortByTempBlocks, 7},
+}
+
+// showTopQueries switches to the top queries view
+func (a *App) showTopQueries() {
+	a.switchView("top_queries", markSortedHeader(topQueryHeaders, topQuerySorts[a.topQuerySort].header))
+}
+
+// nextTopQuerySort switches the top queries view to the next sort column
+func (a *App) nextTopQuerySort() {
+	a.topQuerySort = (a.topQuerySort + 1) % len(topQuerySorts)
+	a.showTopQueries()
+}
+
+// markSortedHeader returns a copy of headers with the descending sorted column marked
+func markSortedHeader(headers []string, column int) []string {
+	return markSortDirection(headers, column, true)
+}
+
+// markSortDirection returns a copy of headers with the sorted column marked by its direction
+func markSortDirection(headers []string, column int, descending bool) []string {
+	marked := make([]string, len(headers))
+	copy(marked, headers)
+	if column >= 0 && column < len(marked) {
+		if descending {
+			marked[column] += " ▼"
+		} else {
+			marked[column] += " ▲"
+		}
+	}
+	return marked
+}
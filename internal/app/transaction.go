@@ -0,0 +1,152 @@
+package app
+
+import (
+	"fmt"
+	"p6s/internal/config"
+	"p6s/internal/db"
+	"regexp"
+	"strings"
+)
+
+// rollbackToSavepoint matches ROLLBACK TO SAVEPOINT, which runs inside the open transaction
+var rollbackToSavepoint = regexp.MustCompile(`(?i)^\s*(rollback|abort)(\s+(work|transaction))?\s+to\b`)
+
+// endPrepared matches COMMIT PREPARED and ROLLBACK PREPARED, which end a prepared transaction
+// rather than the one of the session
+var endPrepared = regexp.MustCompile(`(?i)^\s*(commit|rollback)\s+prepared\b`)
+
+// SetReadWrite sets whether the connection allows writes, read-only being the default
+func (a *App) SetReadWrite(readWrite bool) {
+	a.readWrite = readWrite
+	a.connStr = config.BuildConnStr(a.host, a.port, a.username, a.password, a.database, a.sslmode, readWrite)
+	a.updateConnectionMode()
+}
+
+// updateConnectionMode shows the read-write mode and transaction state in the banner
+func (a *App) updateConnectionMode() {
+	state := db.TransactionNone
+	if a.db != nil {
+		state = a.db.TransactionState()
+	}
+	a.ui.SetConnectionMode(a.readWrite, state != db.TransactionNone, state == db.TransactionFailed)
+}
+
+// handleReadWriteCommand handles "\rw [on|off]", switching the mode of the current session only
+func (a *App) handleReadWriteCommand(cmd string) {
+	parts := strings.Fields(cmd)
+	readWrite := !a.readWrite
+	if len(parts) > 1 {
+		switch parts[1] {
+		case "on":
+			readWrite = true
+		case "off":
+			readWrite = false
+		default:
+			a.ShowError(fmt.Sprintf("Invalid value: %s, usage: \\rw [on|off]", parts[1]))
+			return
+		}
+	}
+
+	if readWrite == a.readWrite {
+		a.ShowInfo(fmt.Sprintf("Session is already %s", connectionModeName(readWrite)))
+		return
+	}
+	if a.db != nil && a.db.InTransaction() {
+		a.ShowError("A transaction is in progress, COMMIT or ROLLBACK it before switching the mode")
+		return
+	}
+	if a.queryBusy {
+		a.ShowError("A query is running, wait for it or cancel it with C before switching the mode")
+		return
+	}
+
+	a.SetReadWrite(readWrite)
+	if err := a.Connect(); err != nil {
+		return
+	}
+	a.ShowInfo(fmt.Sprintf("Session switched to %s", connectionModeName(readWrite)))
+}
+
+// connectionModeName names the read-only or read-write mode
+func connectionModeName(readWrite bool) string {
+	if readWrite {
+		return "READ-WRITE"
+	}
+	return "read-only"
+}
+
+// transactionStatement recognizes a single BEGIN, START TRANSACTION, COMMIT, END, ROLLBACK or ABORT
+// statement typed as custom SQL and returns the transaction command to run for it together
+// with the statement without its trailing semicolon
+func transactionStatement(sqlQuery string) (string, string, bool) {
+	statement := strings.TrimRight(strings.TrimSpace(sqlQuery), "; \t\r\n")
+	if strings.Contains(statement, ";") || rollbackToSavepoint.MatchString(statement) || endPrepared.MatchString(statement) {
+		return "", "", false
+	}
+
+	switch db.FirstKeyword(statement) {
+	case "BEGIN", "START":
+		return "\\begin", statement, true
+	case "COMMIT", "END":
+		return "\\commit", statement, true
+	case "ROLLBACK", "ABORT":
+		return "\\rollback", statement, true
+	}
+	return "", "", false
+}
+
+// handleTransactionCommand runs \begin, \commit or \rollback. statement is the statement typed
+// as custom SQL with its options, empty for the command line
+func (a *App) handleTransactionCommand(command, statement string) {
+	if a.db == nil || !a.db.IsConnected() {
+		a.ui.ConnInfo.SetText("[red]Database not connected. Please configure connection first.[white]\n")
+		return
+	}
+	if a.queryBusy {
+		a.ShowError("A query is running, wait for it or cancel it with C first")
+		return
+	}
+	if a.connectionClosing() {
+		return
+	}
+
+	// An open result cursor lives in the transaction and must be closed before it ends
+	if query := a.customQuery; query != nil {
+		a.customQuery = nil
+		a.queryMore = false
+		query.Close()
+	}
+
+	message, err := a.runTransactionCommand(command, statement)
+	a.updateConnectionMode()
+	if err != nil {
+		a.ShowError(err.Error())
+		return
+	}
+	a.ShowInfo(message)
+}
+
+// runTransactionCommand begins, commits or rolls back the explicit transaction and describes the outcome
+func (a *App) runTransactionCommand(command, statement string) (string, error) {
+	switch command {
+	case "\\begin":
+		message := "Transaction started, run COMMIT or ROLLBACK to end it"
+		if !a.readWrite {
+			message += " (read-only session)"
+		}
+		return message, a.db.Begin(statement)
+	case "\\commit":
+		err := a.db.Commit(statement)
+		return chainedMessage("Transaction committed", a.db.InTransaction()), err
+	}
+	err := a.db.Rollback(statement)
+	return chainedMessage("Transaction rolled back", a.db.InTransaction()), err
+}
+
+// chainedMessage tells that COMMIT or ROLLBACK AND CHAIN started a new transaction
+func chainedMessage(message string, chained bool) string {
+	if chained {
+		return message + ", a new transaction was started with the same characteristics"
+	}
+	return message
+}
@@ -45,4 +45,28 @@ func (a *App) AddTableRow(rowData []string) {
 	for i, cellData := range rowData {
 		a.ui.ConnTable.SetCell(rowCount, i, tview.NewTableCell(cellData))
 	}
+}
+
+// centered wraps a primitive into a centered container for modal pages.
+// A width or height of 0 makes the primitive take 80% of the screen in that direction.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	row := tview.NewFlex().SetDirection(tview.FlexColumn)
+	row.AddItem(nil, 0, 1, false)
+	if width > 0 {
+		row.AddItem(p, width, 0, true)
+	} else {
+		row.AddItem(p, 0, 8, true)
+	}
+	row.AddItem(nil, 0, 1, false)
+
+	center := tview.NewFlex().SetDirection(tview.FlexRow)
+	center.AddItem(nil, 0, 1, false)
+	if height > 0 {
+		center.AddItem(row, height, 0, true)
+	} else {
+		center.AddItem(row, 0, 8, true)
+	}
+	center.AddItem(nil, 0, 1, false)
+
+	return center
 }
\ No newline at end of file
@@ -15,6 +15,11 @@ type Config struct {
 	Password string `json:"password"`
 	Database string `json:"database"`
 	SSLMode  string `json:"sslmode"`
+	// ReadWrite allows writes on this connection, connections are read-only by default
+	ReadWrite bool `json:"read_write,omitempty"`
+	// Guards turns confirmations of destructive statements on or off by kind: ddl, drop, truncate,
+	// dml_without_where and procedure. Kinds that are not listed are guarded.
+	Guards map[string]bool `json:"guards,omitempty"`
 	// K8s related configuration
 	Namespace string `json:"namespace,omitempty"`
 	Pod       string `json:"pod,omitempty"`
@@ -24,8 +29,8 @@ type Config struct {
 	SecretKey string `json:"secret_key,omitempty"`
 }
 
-// getConfigPath returns the path of config file
-func getConfigPath() (string, error) {
+// getConfigDir returns the ~/.p6s directory, creating it if needed
+func getConfigDir() (string, error) {
 	// Get user home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -37,6 +42,15 @@ func getConfigPath() (string, error) {
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return "", fmt.Errorf("unable to create config directory: %v", err)
 	}
+	return configDir, nil
+}
+
+// getConfigPath returns the path of config file
+func getConfigPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
 
 	// Return full path of config file
 	return filepath.Join(configDir, "config.json"), nil
@@ -90,8 +104,12 @@ func SaveConfig(config *Config) error {
 	return nil
 }
 
-// BuildConnStr builds connection string (read-only mode)
-func BuildConnStr(host, port, username, password, database, sslmode string) string {
+// BuildConnStr builds connection string, read-only unless readWrite is set
+func BuildConnStr(host, port, username, password, database, sslmode string, readWrite bool) string {
+	if readWrite {
+		return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s&application_name=p6s",
+			username, password, host, port, database, sslmode)
+	}
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s&default_transaction_read_only=on&application_name=p6s-readonly",
 		username, password, host, port, database, sslmode)
 }
\ No newline at end of file
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"p6s/internal/model"
+	"path/filepath"
+)
+
+// MaxHistoryEntries is the number of query history entries kept
+const MaxHistoryEntries = 1000
+
+// getHistoryPath returns the path of the query history file, one JSON entry per line
+func getHistoryPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "history.jsonl"), nil
+}
+
+// LoadHistory loads the last MaxHistoryEntries entries of the query history, oldest first.
+// Lines that cannot be parsed are skipped.
+func LoadHistory() ([]model.HistoryEntry, error) {
+	historyPath, err := getHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	var entries []model.HistoryEntry
+	scanner := bufio.NewScanner(file)
+	// Statements can be long, the SQL query window takes up to 50000 characters
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry model.HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+
+	if len(entries) > MaxHistoryEntries {
+		entries = entries[len(entries)-MaxHistoryEntries:]
+	}
+	return entries, nil
+}
+
+// AppendHistory appends an entry to the query history file
+func AppendHistory(entry model.HistoryEntry) error {
+	historyPath, err := getHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to serialize history entry: %v", err)
+	}
+
+	// Statements may contain passwords or personal data, keep the file private
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+	return nil
+}
+
+// SaveHistory replaces the query history file with entries, used to drop the oldest entries
+func SaveHistory(entries []model.HistoryEntry) error {
+	historyPath, err := getHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to serialize history entry: %v", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	// Write a temporary file first so that a failure does not lose the history
+	tmpPath := historyPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+	if err := os.Rename(tmpPath, historyPath); err != nil {
+		return fmt.Errorf("failed to replace history file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"p6s/internal/model"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// queryNamePattern restricts saved query names to what is safe as a file name
+var queryNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// getQueriesDir returns the ~/.p6s/queries directory holding one <name>.sql file per saved query
+func getQueriesDir() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	queriesDir := filepath.Join(configDir, "queries")
+	if err := os.MkdirAll(queriesDir, 0700); err != nil {
+		return "", fmt.Errorf("unable to create queries directory: %v", err)
+	}
+	return queriesDir, nil
+}
+
+// getQueryPath returns the file of the saved query name, after checking the name
+func getQueryPath(name string) (string, error) {
+	if !queryNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid query name %q, use letters, digits, _ and -", name)
+	}
+	queriesDir, err := getQueriesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(queriesDir, name+".sql"), nil
+}
+
+// LoadSavedQueries loads the saved queries sorted by name. Files that are not valid query names are skipped.
+func LoadSavedQueries() ([]model.SavedQuery, error) {
+	queriesDir, err := getQueriesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(queriesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queries directory: %v", err)
+	}
+
+	var queries []model.SavedQuery
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".sql")
+		if file.IsDir() || name == file.Name() || !queryNamePattern.MatchString(name) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(queriesDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query %s: %v", name, err)
+		}
+		queries = append(queries, newSavedQuery(name, string(data)))
+	}
+
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].Name < queries[j].Name
+	})
+	return queries, nil
+}
+
+// LoadSavedQuery loads the saved query name
+func LoadSavedQuery(name string) (*model.SavedQuery, error) {
+	queryPath, err := getQueryPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(queryPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no saved query named %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query %s: %v", name, err)
+	}
+
+	query := newSavedQuery(name, string(data))
+	return &query, nil
+}
+
+// SaveQuery saves sql as the query name, replacing a saved query of the same name
+func SaveQuery(name, sql string) error {
+	queryPath, err := getQueryPath(name)
+	if err != nil {
+		return err
+	}
+
+	sql = strings.TrimSpace(sql) + "\n"
+	if err := os.WriteFile(queryPath, []byte(sql), 0600); err != nil {
+		return fmt.Errorf("failed to write query %s: %v", name, err)
+	}
+	return nil
+}
+
+// DeleteSavedQuery deletes the saved query name
+func DeleteSavedQuery(name string) error {
+	queryPath, err := getQueryPath(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(queryPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no saved query named %s", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete query %s: %v", name, err)
+	}
+	return nil
+}
+
+// newSavedQuery builds a saved query from its file, the leading -- comment lines describe it
+func newSavedQuery(name, sql string) model.SavedQuery {
+	var description []string
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		description = append(description, strings.TrimSpace(strings.TrimPrefix(line, "--")))
+	}
+
+	return model.SavedQuery{
+		Name:        name,
+		Description: strings.TrimSpace(strings.Join(description, " ")),
+		SQL:         strings.TrimSpace(sql),
+	}
+}
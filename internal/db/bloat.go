@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// Sort columns accepted by GetBloatEstimates
+const (
+	SortByBloatSize    = "bloat_size"
+	SortByBloatPercent = "bloat_pct"
+	SortByRealSize     = "real_size"
+)
+
+// tableBloatQuery estimates heap bloat from pg_stats average widths and null fractions,
+// following the well known estimation query of ioguix/pgsql-bloat-estimation
+const tableBloatQuery = `SELECT schemaname, tblname, '',
+		(bs * tblpages)::bigint,
+		CASE WHEN tblpages - est_tblpages_ff > 0 THEN ((tblpages - est_tblpages_ff) * bs)::bigint ELSE 0 END,
+		CASE WHEN tblpages > 0 AND tblpages - est_tblpages_ff > 0 THEN (100 * (tblpages - est_tblpages_ff) / tblpages)::float8 ELSE 0 END,
+		fillfactor,
+		is_na
+	FROM (
+		SELECT ceil(reltuples / ((bs - page_hdr) * fillfactor / (tpl_size * 100))) + ceil(toasttuples / 4) AS est_tblpages_ff,
+			tblpages, fillfactor, bs, schemaname, tblname, is_na
+		FROM (
+			SELECT (4 + tpl_hdr_size + tpl_data_size + (2 * ma)
+					- CASE WHEN tpl_hdr_size % ma = 0 THEN ma ELSE tpl_hdr_size % ma END
+					- CASE WHEN ceil(tpl_data_size)::int % ma = 0 THEN ma ELSE ceil(tpl_data_size)::int % ma END
+				) AS tpl_size,
+				(heappages + toastpages) AS tblpages, reltuples, toasttuples, bs, page_hdr, schemaname, tblname, fillfactor, is_na
+			FROM (
+				SELECT ns.nspname AS schemaname, tbl.relname AS tblname, greatest(tbl.reltuples, 0) AS reltuples,
+					tbl.relpages AS heappages, coalesce(toast.relpages, 0) AS toastpages,
+					greatest(coalesce(toast.reltuples, 0), 0) AS toasttuples,
+					coalesce(substring(array_to_string(tbl.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 100) AS fillfactor,
+					current_setting('block_size')::numeric AS bs,
+					CASE WHEN version() ~ 'mingw32' OR version() ~ '64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS ma,
+					24 AS page_hdr,
+					23 + CASE WHEN max(coalesce(s.null_frac, 0)) > 0 THEN (7 + count(s.attname)) / 8 ELSE 0::int END AS tpl_hdr_size,
+					sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 0)) AS tpl_data_size,
+					bool_or(att.atttypid = 'pg_catalog.name'::regtype)
+						OR sum(CASE WHEN att.attnum > 0 THEN 1 ELSE 0 END) <> count(s.attname) AS is_na
+				FROM pg_attribute att
+				JOIN pg_class tbl ON att.attrelid = tbl.oid
+				JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+				LEFT JOIN pg_stats s ON s.schemaname = ns.nspname AND s.tablename = tbl.relname
+					AND s.inherited = false AND s.attname = att.attname
+				LEFT JOIN pg_class toast ON tbl.reltoastrelid = toast.oid
+				WHERE NOT att.attisdropped
+					AND tbl.relkind IN ('r', 'm')
+					AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+					AND ($1::text = '' OR ns.nspname = $1::text)
+					AND ($2::text = '' OR tbl.relname = $2::text)
+				GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10
+			) AS s
+		) AS s2
+		WHERE tpl_size > 0
+	) AS s3`
+
+// indexBloatQuery estimates btree index bloat from pg_stats average widths and null fractions,
+// following the well known estimation query of ioguix/pgsql-bloat-estimation
+const indexBloatQuery = `SELECT nspname, tblname, idxname,
+		(bs * relpages)::bigint,
+		CASE WHEN relpages > est_pages_ff THEN (bs * (relpages - est_pages_ff))::bigint ELSE 0 END,
+		CASE WHEN relpages > est_pages_ff THEN (100 * (relpages - est_pages_ff)::float8 / relpages) ELSE 0 END,
+		fillfactor,
+		is_na
+	FROM (
+		SELECT coalesce(1 + ceil(reltuples / floor((bs - pageopqdata - pagehdr) * fillfactor / (100 * (4 + nulldatahdrwidth)::float))), 0) AS est_pages_ff,
+			bs, nspname, tblname, idxname, relpages, fillfactor, is_na
+		FROM (
+			SELECT maxalign, bs, nspname, tblname, idxname, reltuples, relpages, fillfactor,
+				(index_tuple_hdr_bm + maxalign
+					- CASE WHEN index_tuple_hdr_bm % maxalign = 0 THEN maxalign ELSE index_tuple_hdr_bm % maxalign END
+					+ nulldatawidth + maxalign
+					- CASE WHEN nulldatawidth = 0 THEN 0 WHEN nulldatawidth::integer % maxalign = 0 THEN maxalign ELSE nulldatawidth::integer % maxalign END
+				)::numeric AS nulldatahdrwidth,
+				pagehdr, pageopqdata, is_na
+			FROM (
+				SELECT n.nspname, i.tblname, i.idxname, greatest(i.reltuples, 0) AS reltuples, i.relpages, i.fillfactor,
+					current_setting('block_size')::numeric AS bs,
+					CASE WHEN version() ~ 'mingw32' OR version() ~ '64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS maxalign,
+					24 AS pagehdr,
+					16 AS pageopqdata,
+					CASE WHEN max(coalesce(s.null_frac, 0)) = 0 THEN 8 ELSE 8 + ((32 + 8 - 1) / 8) END AS index_tuple_hdr_bm,
+					sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 1024)) AS nulldatawidth,
+					max(CASE WHEN i.atttypid = 'pg_catalog.name'::regtype THEN 1 ELSE 0 END) > 0 AS is_na
+				FROM (
+					SELECT ct.relname AS tblname, ct.relnamespace, ic.idxname, ic.reltuples, ic.relpages, ic.fillfactor,
+						coalesce(a1.attname, a2.attname) AS attname,
+						coalesce(a1.atttypid, a2.atttypid) AS atttypid,
+						CASE WHEN a1.attnum IS NULL THEN ic.idxname ELSE ct.relname END AS attrelname
+					FROM (
+						SELECT ci.relname AS idxname, ci.reltuples, ci.relpages, i.indrelid AS tbloid, i.indexrelid AS idxoid,
+							coalesce(substring(array_to_string(ci.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 90) AS fillfactor,
+							string_to_array(textin(int2vectorout(i.indkey)), ' ')::int[] AS indkey,
+							generate_series(1, i.indnatts) AS attpos
+						FROM pg_index i
+						JOIN pg_class ci ON ci.oid = i.indexrelid
+						WHERE ci.relam = (SELECT oid FROM pg_am WHERE amname = 'btree')
+							AND ci.relpages > 0
+					) AS ic
+					JOIN pg_class ct ON ct.oid = ic.tbloid
+					LEFT JOIN pg_attribute a1 ON ic.indkey[ic.attpos] <> 0 AND a1.attrelid = ic.tbloid AND a1.attnum = ic.indkey[ic.attpos]
+					LEFT JOIN pg_attribute a2 ON ic.indkey[ic.attpos] = 0 AND a2.attrelid = ic.idxoid AND a2.attnum = ic.attpos
+				) i
+				JOIN pg_namespace n ON n.oid = i.relnamespace
+				JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = i.attrelname AND s.attname = i.attname
+				WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+					AND ($1::text = '' OR n.nspname = $1::text)
+					AND ($2::text = '' OR i.tblname = $2::text)
+				GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10
+			) AS rows_data_stats
+		) AS rows_hdr_pdg_stats
+	) AS relation_stats`
+
+// GetBloatEstimates estimates table and btree index bloat ordered by sortBy, schema and table
+// restrict the estimates to a single table and its indexes when not empty
+func (p *PostgresDB) GetBloatEstimates(schema, table, sortBy string) ([]model.BloatEstimate, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var estimates []model.BloatEstimate
+
+	for _, source := range []struct {
+		kind  string
+		query string
+	}{
+		{"table", tableBloatQuery},
+		{"index", indexBloatQuery},
+	} {
+		rows, err := p.db.QueryContext(ctx, source.query, schema, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s bloat: %v", source.kind, err)
+		}
+
+		for rows.Next() {
+			estimate := model.BloatEstimate{Kind: source.kind}
+			if err := rows.Scan(
+				&estimate.Schema,
+				&estimate.Table,
+				&estimate.Index,
+				&estimate.RealSize,
+				&estimate.BloatSize,
+				&estimate.BloatPercent,
+				&estimate.Fillfactor,
+				&estimate.Unreliable,
+			); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to parse %s bloat: %v", source.kind, err)
+			}
+			estimates = append(estimates, estimate)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate %s bloat: %v", source.kind, err)
+		}
+	}
+
+	sort.SliceStable(estimates, func(i, j int) bool {
+		a, b := estimates[i], estimates[j]
+		switch sortBy {
+		case SortByBloatPercent:
+			return a.BloatPercent > b.BloatPercent
+		case SortByRealSize:
+			return a.RealSize > b.RealSize
+		default:
+			return a.BloatSize > b.BloatSize
+		}
+	})
+
+	return estimates, nil
+}
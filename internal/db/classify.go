@@ -0,0 +1,510 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Guards of the destructive statement guard, also the keys of the guards setting of a connection profile
+const (
+	GuardDDL             = "ddl"
+	GuardDrop            = "drop"
+	GuardTruncate        = "truncate"
+	GuardDMLWithoutWhere = "dml_without_where"
+	GuardProcedure       = "procedure"
+)
+
+// StatementClass is what ClassifyStatement found out about a statement
+type StatementClass struct {
+	// Type names the statement, e.g. "DELETE without WHERE" or "DROP TABLE"
+	Type string
+	// Guard asks for confirmation before the statement runs, empty for statements that need none
+	Guard string
+	// Tables are the tables a TRUNCATE or DROP TABLE statement empties
+	Tables []string
+	// Explained is the statement an EXPLAIN ANALYZE executes, the estimate is made for it
+	Explained string
+}
+
+// ddlCommands are the statements guarded as DDL, DROP and TRUNCATE have guards of their own
+var ddlCommands = map[string]bool{
+	"CREATE": true, "ALTER": true, "COMMENT": true, "GRANT": true, "REVOKE": true,
+	"SECURITY": true, "IMPORT": true, "REINDEX": true, "CLUSTER": true, "REFRESH": true,
+}
+
+// objectModifiers are the words that can come before the object type in CREATE, ALTER and DROP
+var objectModifiers = map[string]bool{
+	"OR": true, "REPLACE": true, "TEMP": true, "TEMPORARY": true, "UNLOGGED": true, "UNIQUE": true,
+	"MATERIALIZED": true, "FOREIGN": true, "GLOBAL": true, "LOCAL": true, "RECURSIVE": true,
+	"TRUSTED": true, "PROCEDURAL": true, "DEFAULT": true, "CONSTRAINT": true, "EVENT": true,
+	"TEXT": true, "SEARCH": true, "ACCESS": true, "DATA": true, "OPERATOR": true,
+}
+
+// ClassifyStatement tells DDL, DROP, TRUNCATE, UPDATE or DELETE without WHERE, MERGE, DO and CALL
+// apart from other statements, looking through EXPLAIN ANALYZE at the statement it executes. It
+// only looks at keywords outside of literals and comments and does not parse the statement, so it
+// errs on the side of asking.
+func ClassifyStatement(statement string) StatementClass {
+	tokens := scanSQL(statement)
+	if len(tokens) == 0 {
+		return StatementClass{}
+	}
+
+	command := tokens[0].keyword()
+	switch {
+	case command == "EXPLAIN":
+		explained, analyze := explainedStatement(tokens[1:])
+		if !analyze || explained == nil {
+			return StatementClass{Type: command}
+		}
+		inner := statement[explained[0].start:]
+		class := ClassifyStatement(inner)
+		class.Type = "EXPLAIN ANALYZE " + class.Type
+		if class.Explained == "" {
+			class.Explained = inner
+		}
+		return class
+	case command == "DO" || command == "CALL":
+		// The body or the procedure may change anything
+		return StatementClass{Type: command, Guard: GuardProcedure}
+	case command == "DROP":
+		objectType, rest := statementObjectType(tokens[1:])
+		class := StatementClass{Type: strings.TrimSpace("DROP " + objectType), Guard: GuardDrop}
+		if objectType == "TABLE" {
+			if len(rest) >= 2 && rest[0].keyword() == "IF" && rest[1].keyword() == "EXISTS" {
+				rest = rest[2:]
+			}
+			class.Tables = relationNames(rest)
+		}
+		return class
+	case command == "TRUNCATE":
+		rest := tokens[1:]
+		if len(rest) > 0 && rest[0].keyword() == "TABLE" {
+			rest = rest[1:]
+		}
+		return StatementClass{Type: "TRUNCATE", Guard: GuardTruncate, Tables: relationNames(rest)}
+	case ddlCommands[command]:
+		class := StatementClass{Type: command, Guard: GuardDDL}
+		if command == "CREATE" || command == "ALTER" {
+			objectType, rest := statementObjectType(tokens[1:])
+			class.Type = strings.TrimSpace(class.Type + " " + objectType)
+			// ALTER TABLE ... DROP COLUMN and the like lose data like DROP does
+			if command == "ALTER" && containsKeyword(rest, "DROP") {
+				class.Type += " ... DROP"
+				class.Guard = GuardDrop
+			}
+		}
+		return class
+	case command == "UPDATE" || command == "DELETE" || command == "MERGE" || command == "WITH":
+		unfiltered := unfilteredDML(tokens)
+		if unfiltered == "MERGE" {
+			// MERGE has no WHERE, it changes every row its join condition matches
+			return StatementClass{Type: unfiltered, Guard: GuardDMLWithoutWhere}
+		}
+		if unfiltered != "" {
+			return StatementClass{Type: unfiltered + " without WHERE", Guard: GuardDMLWithoutWhere}
+		}
+		return StatementClass{Type: command}
+	}
+	return StatementClass{Type: command}
+}
+
+// readOnlySettings are the settings that make the transactions of a session read-only
+var readOnlySettings = map[string]bool{"default_transaction_read_only": true, "transaction_read_only": true}
+
+// EnablesWrites reports whether a statement may lift the read-only mode of a session: BEGIN, START
+// TRANSACTION, SET TRANSACTION and SET SESSION CHARACTERISTICS with READ WRITE, SET or RESET of
+// transaction_read_only or default_transaction_read_only, set_config of them or of a setting
+// that is not a literal, and DO blocks naming them. Reading the settings is fine.
+func EnablesWrites(statement string) bool {
+	tokens := scanSQL(statement)
+	if len(tokens) == 0 {
+		return false
+	}
+
+	switch tokens[0].keyword() {
+	case "BEGIN", "START":
+		return hasReadWrite(tokens)
+	case "SET":
+		rest := tokens[1:]
+		if len(rest) > 0 && (rest[0].keyword() == "SESSION" || rest[0].keyword() == "LOCAL") {
+			rest = rest[1:]
+		}
+		if len(rest) > 0 && (rest[0].keyword() == "TRANSACTION" || rest[0].keyword() == "CHARACTERISTICS") {
+			return hasReadWrite(rest)
+		}
+		return len(rest) > 0 && readOnlySettings[settingName(rest[0])]
+	case "RESET":
+		return len(tokens) > 1 && readOnlySettings[settingName(tokens[1])]
+	case "DO":
+		// The body is a string, any mention of the settings in it counts
+		for _, token := range tokens {
+			if token.kind == tokenString && strings.Contains(strings.ToLower(token.text), "transaction_read_only") {
+				return true
+			}
+		}
+	}
+
+	for i := 0; i+2 < len(tokens); i++ {
+		if tokens[i].keyword() != "SET_CONFIG" || tokens[i+1].text != "(" {
+			continue
+		}
+		name := tokens[i+2]
+		if name.kind != tokenString || readOnlySettings[strings.ToLower(stringValue(name))] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReadWrite reports whether the transaction modes of a statement include READ WRITE
+func hasReadWrite(tokens []sqlToken) bool {
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].keyword() == "READ" && tokens[i+1].keyword() == "WRITE" {
+			return true
+		}
+	}
+	return false
+}
+
+// settingName returns the lower-cased name of a setting written as a word or quoted identifier
+func settingName(token sqlToken) string {
+	switch token.kind {
+	case tokenWord:
+		return strings.ToLower(token.text)
+	case tokenQuotedIdent:
+		return strings.Trim(token.text, `"`)
+	}
+	return ""
+}
+
+// stringValue returns the value of a string literal token
+func stringValue(token sqlToken) string {
+	text := token.text
+	if tag, ok := dollarQuoteTag(text); ok && text[0] == '$' {
+		return strings.TrimSuffix(text[len(tag):], tag)
+	}
+	if text[0] == 'E' || text[0] == 'e' {
+		text = text[1:]
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "'"), "'")
+	return strings.ReplaceAll(text, "''", "'")
+}
+
+// explainedStatement returns the tokens of the statement an EXPLAIN explains and whether the
+// ANALYZE option makes EXPLAIN execute it, in the EXPLAIN ANALYZE VERBOSE as well as in the
+// EXPLAIN (ANALYZE, ...) form
+func explainedStatement(tokens []sqlToken) ([]sqlToken, bool) {
+	analyze := false
+	if len(tokens) > 0 && tokens[0].text == "(" {
+		depth := 0
+		for i, token := range tokens {
+			switch token.text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+				if depth == 0 {
+					return tokens[i+1:], analyze
+				}
+			}
+			keyword := token.keyword()
+			if depth == 1 && (keyword == "ANALYZE" || keyword == "ANALYSE") {
+				// ANALYZE FALSE, OFF and 0 turn it off, any other value or none turns it on
+				analyze = true
+				if i+1 < len(tokens) {
+					value := strings.ToUpper(tokens[i+1].text)
+					analyze = value != "FALSE" && value != "OFF" && value != "0"
+				}
+			}
+		}
+		return nil, analyze
+	}
+
+	for i, token := range tokens {
+		switch token.keyword() {
+		case "ANALYZE", "ANALYSE":
+			analyze = true
+		case "VERBOSE":
+		default:
+			return tokens[i:], analyze
+		}
+	}
+	return nil, analyze
+}
+
+// statementObjectType returns the object type following CREATE, ALTER or DROP, e.g. TABLE or
+// MATERIALIZED VIEW, and the tokens after it
+func statementObjectType(tokens []sqlToken) (string, []sqlToken) {
+	var words []string
+	for i, token := range tokens {
+		keyword := token.keyword()
+		if keyword == "" {
+			return strings.Join(words, " "), tokens[i:]
+		}
+		words = append(words, keyword)
+		if !objectModifiers[keyword] {
+			return strings.Join(words, " "), tokens[i+1:]
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// containsKeyword reports whether keyword appears outside of parentheses
+func containsKeyword(tokens []sqlToken, keyword string) bool {
+	depth := 0
+	for _, token := range tokens {
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 && token.keyword() == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// unfilteredDML returns UPDATE or DELETE if the statement, or one of its WITH queries, updates or
+// deletes without a WHERE clause
+func unfilteredDML(tokens []sqlToken) string {
+	depth := 0
+	for i, token := range tokens {
+		switch token.text {
+		case "(":
+			depth++
+			continue
+		case ")":
+			depth--
+			continue
+		}
+
+		keyword := token.keyword()
+		if keyword != "UPDATE" && keyword != "DELETE" && keyword != "MERGE" {
+			continue
+		}
+		// A data-modifying statement starts the text, a WITH query or follows the WITH queries;
+		// FOR UPDATE, ON DELETE, DO UPDATE and the like are no statements
+		if i > 0 && tokens[i-1].text != "(" && tokens[i-1].text != ")" {
+			continue
+		}
+		if keyword == "MERGE" || !hasWhereClause(tokens[i+1:]) {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// hasWhereClause reports whether WHERE follows at the level of the statement, before the
+// parenthesis closing a WITH query
+func hasWhereClause(tokens []sqlToken) bool {
+	depth := 0
+	for _, token := range tokens {
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+		if depth == 0 && token.keyword() == "WHERE" {
+			return true
+		}
+	}
+	return false
+}
+
+// relationNames returns the comma-separated relation names a list starts with, as written, e.g.
+// public.orders or "Orders". ONLY and the * of TRUNCATE are skipped.
+func relationNames(tokens []sqlToken) []string {
+	var names []string
+	name := ""
+	for _, token := range tokens {
+		switch {
+		case token.kind == tokenWord || token.kind == tokenQuotedIdent:
+			if name == "" && token.keyword() == "ONLY" {
+				continue
+			}
+			if name != "" && !strings.HasSuffix(name, ".") {
+				// A keyword such as CASCADE ends the list
+				return append(names, name)
+			}
+			name += token.text
+		case token.text == ".":
+			name += "."
+		case token.text == "*":
+		case token.text == ",":
+			if name != "" {
+				names = append(names, name)
+			}
+			name = ""
+		default:
+			if name != "" {
+				names = append(names, name)
+			}
+			return names
+		}
+	}
+	if name != "" {
+		names = append(names, name)
+	}
+	return names
+}
+
+// explainNode is a plan node of EXPLAIN (FORMAT JSON)
+type explainNode struct {
+	NodeType string        `json:"Node Type"`
+	PlanRows float64       `json:"Plan Rows"`
+	Plans    []explainNode `json:"Plans"`
+}
+
+// rowQueryer runs a query returning at most one row, on the pool or a reserved connection
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// RowEstimator estimates the rows of guarded statements on the connection of the explicit
+// transaction or script that was open when it was created, so that EXPLAIN sees their tables and
+// settings. Stop must be called before the statements run on that connection.
+type RowEstimator struct {
+	db      *PostgresDB
+	tx      *transaction
+	session *session
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// mu is held while an estimate runs, Stop waits for it
+	mu sync.Mutex
+}
+
+// NewRowEstimator prepares the estimates of guarded statements
+func (p *PostgresDB) NewRowEstimator() *RowEstimator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RowEstimator{
+		db:      p,
+		tx:      p.tx,
+		session: p.session,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Stop cancels the running estimate and waits until it returned, so that its connection is free
+func (e *RowEstimator) Stop() {
+	e.cancel()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+}
+
+// EstimateAffectedRows estimates how many rows a guarded statement changes or removes: from the
+// plan of EXPLAIN for UPDATE and DELETE, from the table statistics for TRUNCATE and DROP TABLE.
+// args are the values of the $n parameters of the statement. ok is false when the statement allows no estimate.
+func (e *RowEstimator) EstimateAffectedRows(statement string, class StatementClass, args ...interface{}) (rows int64, ok bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.db.db == nil {
+		return 0, false, fmt.Errorf("database not connected")
+	}
+	if e.ctx.Err() != nil {
+		return 0, false, ErrQueryCancelled
+	}
+
+	var queryer rowQueryer = e.db.db
+	if e.tx != nil {
+		// A failed transaction runs nothing until ROLLBACK
+		if e.tx.isFailed() {
+			return 0, false, nil
+		}
+		queryer = e.tx.conn
+	} else if e.session != nil {
+		queryer = e.session.conn
+	}
+
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+	defer cancel()
+
+	if e.tx != nil {
+		// A savepoint keeps a failing EXPLAIN from aborting the explicit transaction
+		if _, err := e.tx.conn.ExecContext(ctx, "SAVEPOINT p6s_estimate"); err != nil {
+			return 0, false, fmt.Errorf("failed to create savepoint: %v", err)
+		}
+		defer func() {
+			undo := "RELEASE SAVEPOINT p6s_estimate"
+			if err != nil {
+				undo = "ROLLBACK TO SAVEPOINT p6s_estimate"
+			}
+			// The estimate may have been cancelled, the savepoint is still undone
+			undoCtx, undoCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer undoCancel()
+			if _, undoErr := e.tx.conn.ExecContext(undoCtx, undo); undoErr != nil {
+				e.tx.setFailed()
+				if err == nil {
+					rows, ok, err = 0, false, fmt.Errorf("failed to release savepoint: %v", undoErr)
+				}
+			}
+		}()
+	}
+
+	switch {
+	case class.Guard == GuardDMLWithoutWhere:
+		var plan string
+		if class.Explained != "" {
+			statement = class.Explained
+		}
+		if err := queryer.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+statement, args...).Scan(&plan); err != nil {
+			return 0, false, fmt.Errorf("failed to explain statement: %v", err)
+		}
+
+		var plans []struct {
+			Plan explainNode `json:"Plan"`
+		}
+		if err := json.Unmarshal([]byte(plan), &plans); err != nil {
+			return 0, false, fmt.Errorf("failed to parse plan: %v", err)
+		}
+		if len(plans) == 0 {
+			return 0, false, nil
+		}
+		rows, ok := modifiedRows(plans[0].Plan)
+		return rows, ok, nil
+	case len(class.Tables) > 0:
+		var count int
+		err := queryer.QueryRowContext(ctx, `
+			SELECT count(c.oid), coalesce(sum(greatest(c.reltuples, 0)), 0)::bigint
+			FROM unnest($1::text[]) AS t(name)
+			JOIN pg_class c ON c.oid = to_regclass(t.name)`, pq.Array(class.Tables)).Scan(&count, &rows)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to get table statistics: %v", err)
+		}
+		return rows, count > 0, nil
+	}
+	return 0, false, nil
+}
+
+// modifiedRows returns the estimated rows of the first ModifyTable node of the plan, which are the
+// rows its input produces: the node itself only returns rows for RETURNING
+func modifiedRows(node explainNode) (int64, bool) {
+	if node.NodeType == "ModifyTable" {
+		if len(node.Plans) == 0 {
+			return 0, false
+		}
+		return int64(node.Plans[0].PlanRows), true
+	}
+	for _, child := range node.Plans {
+		if rows, ok := modifiedRows(child); ok {
+			return rows, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnablesWrites(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      bool
+	}{
+		{"BEGIN READ WRITE", true},
+		{"begin isolation level serializable, read write", true},
+		{"START TRANSACTION READ WRITE", true},
+		{"SET TRANSACTION READ WRITE", true},
+		{"SET SESSION CHARACTERISTICS AS TRANSACTION READ WRITE", true},
+		{"SET default_transaction_read_only = off", true},
+		{"set session default_transaction_read_only to false", true},
+		{"SET LOCAL transaction_read_only = off", true},
+		{`SET "default_transaction_read_only" = off`, true},
+		{"RESET default_transaction_read_only", true},
+		{"SELECT set_config('default_transaction_read_only', 'off', false)", true},
+		{"SELECT pg_catalog.set_config('transaction_read_only', 'off', true)", true},
+		{"SELECT set_config(name, 'off', false) FROM settings", true},
+		{"DO $$ BEGIN PERFORM set_config('default_transaction_read_only', 'off', false); END $$", true},
+
+		{"BEGIN", false},
+		{"BEGIN READ ONLY", false},
+		{"START TRANSACTION ISOLATION LEVEL REPEATABLE READ", false},
+		{"SET TRANSACTION READ ONLY", false},
+		{"SET search_path = public", false},
+		{"RESET ALL", false},
+		{"SHOW transaction_read_only", false},
+		{"SHOW default_transaction_read_only", false},
+		{"SELECT current_setting('default_transaction_read_only')", false},
+		{"SELECT * FROM pg_settings WHERE name = 'default_transaction_read_only'", false},
+		{"SELECT set_config('search_path', 'public', false)", false},
+		{"SELECT set_config('x$', 'off', false)", false},
+		{"-- SET default_transaction_read_only = off\nSELECT 1", false},
+		{"DO $$ BEGIN RAISE NOTICE 'hello'; END $$", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := EnablesWrites(tt.statement); got != tt.want {
+			t.Errorf("EnablesWrites(%q) = %v, want %v", tt.statement, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      StatementClass
+	}{
+		{"select", "SELECT * FROM users", StatementClass{Type: "SELECT"}},
+		{"empty", "  -- nothing\n", StatementClass{}},
+		{"delete with where", "DELETE FROM users WHERE id = 1", StatementClass{Type: "DELETE"}},
+		{"delete without where", "DELETE FROM users",
+			StatementClass{Type: "DELETE without WHERE", Guard: GuardDMLWithoutWhere}},
+		{"delete using without where", "DELETE FROM orders USING users",
+			StatementClass{Type: "DELETE without WHERE", Guard: GuardDMLWithoutWhere}},
+		{"delete using with where", "DELETE FROM orders o USING users u WHERE o.user_id = u.id",
+			StatementClass{Type: "DELETE"}},
+		{"update without where", "update users set active = false",
+			StatementClass{Type: "UPDATE without WHERE", Guard: GuardDMLWithoutWhere}},
+		{"where in a literal", "UPDATE users SET note = 'WHERE id = 1'",
+			StatementClass{Type: "UPDATE without WHERE", Guard: GuardDMLWithoutWhere}},
+		{"where in a comment", "DELETE FROM users /* WHERE id = 1 */",
+			StatementClass{Type: "DELETE without WHERE", Guard: GuardDMLWithoutWhere}},
+		{"with delete without where", "WITH old AS (SELECT id FROM users WHERE age > 90) DELETE FROM sessions",
+			StatementClass{Type: "DELETE without WHERE", Guard: GuardDMLWithoutWhere}},
+		{"with delete with where", "WITH old AS (SELECT id FROM users) DELETE FROM sessions WHERE user_id IN (SELECT id FROM old)",
+			StatementClass{Type: "WITH"}},
+		{"merge", "MERGE INTO stock s USING deliveries d ON s.item = d.item WHEN MATCHED THEN UPDATE SET qty = s.qty + d.qty",
+			StatementClass{Type: "MERGE", Guard: GuardDMLWithoutWhere}},
+		{"truncate", "TRUNCATE TABLE users, public.orders",
+			StatementClass{Type: "TRUNCATE", Guard: GuardTruncate, Tables: []string{"users", "public.orders"}}},
+		{"drop table", "DROP TABLE IF EXISTS users CASCADE",
+			StatementClass{Type: "DROP TABLE", Guard: GuardDrop, Tables: []string{"users"}}},
+		{"drop index", "DROP INDEX users_email_idx", StatementClass{Type: "DROP INDEX", Guard: GuardDrop}},
+		{"create table", "CREATE UNLOGGED TABLE t (id int)", StatementClass{Type: "CREATE UNLOGGED TABLE", Guard: GuardDDL}},
+		{"alter table drop column", "ALTER TABLE users DROP COLUMN email",
+			StatementClass{Type: "ALTER TABLE ... DROP", Guard: GuardDrop}},
+		{"create function begin atomic", "CREATE FUNCTION one() RETURNS int LANGUAGE sql BEGIN ATOMIC SELECT 1; END",
+			StatementClass{Type: "CREATE FUNCTION", Guard: GuardDDL}},
+		{"do", "DO $$ BEGIN DELETE FROM users; END $$", StatementClass{Type: "DO", Guard: GuardProcedure}},
+		{"call", "CALL cleanup()", StatementClass{Type: "CALL", Guard: GuardProcedure}},
+		{"explain", "EXPLAIN DELETE FROM users", StatementClass{Type: "EXPLAIN"}},
+		{"explain analyze", "EXPLAIN ANALYZE DELETE FROM users",
+			StatementClass{Type: "EXPLAIN ANALYZE DELETE without WHERE", Guard: GuardDMLWithoutWhere, Explained: "DELETE FROM users"}},
+		{"explain (analyze)", "EXPLAIN (ANALYZE, BUFFERS) UPDATE users SET active = false",
+			StatementClass{Type: "EXPLAIN ANALYZE UPDATE without WHERE", Guard: GuardDMLWithoutWhere, Explained: "UPDATE users SET active = false"}},
+		{"explain (analyze false)", "EXPLAIN (ANALYZE false) DELETE FROM users", StatementClass{Type: "EXPLAIN"}},
+		{"explain analyze select", "EXPLAIN ANALYZE SELECT 1",
+			StatementClass{Type: "EXPLAIN ANALYZE SELECT", Explained: "SELECT 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyStatement(tt.statement)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClassifyStatement(%q) = %+v, want %+v", tt.statement, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,460 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// resultCursor is the name of the server-side cursor custom queries are read through
+const resultCursor = "p6s_results"
+
+// CursorIdleTimeout bounds how long the transaction of a result cursor may stay idle while the
+// results are browsed, it holds back vacuum and keeps its locks until then
+const CursorIdleTimeout = 10 * time.Minute
+
+// CustomQuery is a custom SQL statement executed on a dedicated connection so that it
+// can be cancelled both client-side and with pg_cancel_backend on the server.
+//
+// Rows are read page by page: queries returning rows are declared as a server-side cursor
+// in a transaction, so every FETCH gets its own statement timeout; INSERT, UPDATE and DELETE
+// without RETURNING report their affected rows; other statements are streamed from the open
+// result set. The connection stays reserved until all rows were
+// read or Close is called. Inside an explicit transaction or a script the query runs on the
+// connection of the transaction or script instead.
+type CustomQuery struct {
+	db      *PostgresDB
+	tx      *transaction
+	session *session
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+
+	conn        *sql.Conn
+	backend     int
+	rows        *sql.Rows
+	cursor      bool
+	exhausted   bool
+	columns     []string
+	columnTypes []*sql.ColumnType
+	pending     [][]interface{}
+	affected    int64
+	hasAffected bool
+
+	mu        sync.Mutex
+	pid       int
+	cancelled bool
+}
+
+// ErrQueryCancelled is returned by CustomQuery.Run and Fetch when the query was cancelled by the user
+var ErrQueryCancelled = errors.New("query cancelled")
+
+// NewCustomQuery prepares a cancellable custom query, timeout 0 disables the statement timeout
+func (p *PostgresDB) NewCustomQuery(timeout time.Duration) *CustomQuery {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CustomQuery{
+		db:      p,
+		tx:      p.tx,
+		session: p.session,
+		ctx:     ctx,
+		cancel:  cancel,
+		timeout: timeout,
+	}
+}
+
+// Run executes the statement with the values of its $n parameters and returns its first page of up
+// to pageSize rows as typed values together with the column names. more reports whether further
+// rows can be read with Fetch.
+func (q *CustomQuery) Run(sqlQuery string, pageSize int, args ...interface{}) (results [][]interface{}, columns []string, more bool, err error) {
+	if q.db.db == nil {
+		return nil, nil, false, fmt.Errorf("database not connected")
+	}
+
+	if err := q.open(); err != nil {
+		q.Close()
+		return nil, nil, false, q.wrapError(err)
+	}
+
+	if err := q.start(sqlQuery, args); err != nil {
+		q.Close()
+		return nil, nil, false, q.wrapError(err)
+	}
+
+	results, more, err = q.Fetch(pageSize)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return results, q.columns, more, nil
+}
+
+// open reserves a connection and sets its statement timeout
+func (q *CustomQuery) open() error {
+	var pid int
+	if q.tx != nil {
+		q.conn, pid = q.tx.conn, q.tx.pid
+	} else if q.session != nil {
+		q.conn, pid = q.session.conn, q.session.pid
+	} else {
+		conn, err := q.db.db.Conn(q.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get connection: %v", err)
+		}
+		q.conn = conn
+
+		if err := conn.QueryRowContext(q.ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+			return fmt.Errorf("failed to get backend PID: %v", err)
+		}
+		q.backend = pid
+		q.db.addBackend(pid)
+	}
+
+	// statement_timeout is set per session, Close resets it before the connection goes back to the pool
+	if _, err := q.conn.ExecContext(q.ctx, "SELECT set_config('statement_timeout', $1, false)", fmt.Sprintf("%dms", q.timeout.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to set statement timeout: %v", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.cancelled {
+		return ErrQueryCancelled
+	}
+	q.pid = pid
+	return nil
+}
+
+// start declares the result cursor, falling back to streaming the statement when it cannot be declared
+func (q *CustomQuery) start(sqlQuery string, args []interface{}) error {
+	if returnsRows(sqlQuery) {
+		// A savepoint keeps a failing DECLARE from aborting the explicit transaction
+		begin, undo := "BEGIN", "ROLLBACK"
+		if q.tx != nil {
+			begin, undo = "SAVEPOINT p6s_cursor", "ROLLBACK TO SAVEPOINT p6s_cursor"
+		}
+
+		if _, err := q.conn.ExecContext(q.ctx, begin); err != nil {
+			return fmt.Errorf("failed to begin transaction: %v", err)
+		}
+		if q.tx == nil {
+			// The server ends the session rather than let an abandoned cursor hold its snapshot forever
+			if _, err := q.conn.ExecContext(q.ctx, "SELECT set_config('idle_in_transaction_session_timeout', $1, true)",
+				fmt.Sprintf("%dms", CursorIdleTimeout.Milliseconds())); err != nil {
+				return fmt.Errorf("failed to set idle timeout: %v", err)
+			}
+		}
+		_, err := q.conn.ExecContext(q.ctx, fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", resultCursor, sqlQuery), args...)
+		if err == nil {
+			q.cursor = true
+			if q.tx != nil {
+				if _, err := q.conn.ExecContext(q.ctx, "RELEASE SAVEPOINT p6s_cursor"); err != nil {
+					return fmt.Errorf("failed to release savepoint: %v", err)
+				}
+			}
+			return nil
+		}
+		if q.isCancelled() {
+			return ErrQueryCancelled
+		}
+		// e.g. data-modifying statements in WITH cannot be declared as a cursor
+		if _, err := q.conn.ExecContext(q.ctx, undo); err != nil {
+			return fmt.Errorf("failed to roll back transaction: %v", err)
+		}
+	}
+
+	if reportsAffectedRows(sqlQuery) {
+		result, err := q.conn.ExecContext(q.ctx, sqlQuery, args...)
+		if err != nil {
+			return fmt.Errorf("failed to execute SQL query: %v", err)
+		}
+		if affected, err := result.RowsAffected(); err == nil {
+			q.affected, q.hasAffected = affected, true
+		}
+		q.exhausted = true
+		return nil
+	}
+
+	rows, err := q.conn.QueryContext(q.ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute SQL query: %v", err)
+	}
+	q.rows = rows
+	return q.readColumns(rows)
+}
+
+// Fetch returns the next page of up to pageSize rows. The query is closed once all rows were read
+// or reading failed.
+func (q *CustomQuery) Fetch(pageSize int) ([][]interface{}, bool, error) {
+	// One row is read ahead so that more is only reported when rows really remain
+	page := q.pending
+	q.pending = nil
+
+	if !q.exhausted {
+		rows, err := q.read(pageSize + 1 - len(page))
+		if err != nil {
+			q.Close()
+			return nil, false, q.wrapError(err)
+		}
+		page = append(page, rows...)
+	}
+
+	if len(page) > pageSize {
+		q.pending = page[pageSize:]
+		return page[:pageSize], true, nil
+	}
+
+	q.Close()
+	return page, false, nil
+}
+
+// read reads up to n rows from the cursor or the streamed result set
+func (q *CustomQuery) read(n int) ([][]interface{}, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	rows := q.rows
+	if q.cursor {
+		var err error
+		rows, err = q.conn.QueryContext(q.ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", n, resultCursor))
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "25P03" {
+				return nil, fmt.Errorf("the results were idle for more than %s and are no longer available, run the query again", CursorIdleTimeout)
+			}
+			return nil, fmt.Errorf("failed to fetch rows: %v", err)
+		}
+		defer rows.Close()
+
+		if q.columns == nil {
+			if err := q.readColumns(rows); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	results, err := scanRows(rows, q.columnTypes, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) < n {
+		q.exhausted = true
+	}
+	return results, nil
+}
+
+// readColumns records the column names and types of the result set
+func (q *CustomQuery) readColumns(rows *sql.Rows) error {
+	columns, columnTypes, err := resultColumns(rows)
+	if err != nil {
+		return err
+	}
+	q.columns = columns
+	q.columnTypes = columnTypes
+	return nil
+}
+
+// RowsAffected returns the number of rows an INSERT, UPDATE or DELETE without RETURNING changed,
+// ok is false for other statements
+func (q *CustomQuery) RowsAffected() (rows int64, ok bool) {
+	return q.affected, q.hasAffected
+}
+
+// Close ends the query and releases its connection. It must not be called while Run or Fetch is running.
+func (q *CustomQuery) Close() {
+	q.mu.Lock()
+	conn := q.conn
+	q.conn = nil
+	// The backend may serve other queries from now on and must no longer be signalled
+	q.pid = 0
+	q.mu.Unlock()
+
+	defer q.cancel()
+	if conn == nil {
+		return
+	}
+	if q.backend != 0 {
+		q.db.removeBackend(q.backend)
+	}
+
+	if q.rows != nil {
+		// Closing a result set reads it to the end, cancel the statement instead unless
+		// that would close the connection of the explicit transaction or script
+		if !q.exhausted && !q.Borrowed() {
+			q.cancel()
+		}
+		q.rows.Close()
+		q.rows = nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	if q.cursor {
+		if q.tx != nil {
+			_, err = conn.ExecContext(ctx, "CLOSE "+resultCursor)
+		} else {
+			_, err = conn.ExecContext(ctx, "COMMIT")
+		}
+	}
+	// The connection of an explicit transaction is released by COMMIT or ROLLBACK, the one of a
+	// script by EndSession
+	if q.Borrowed() {
+		if err == nil {
+			conn.ExecContext(ctx, "RESET statement_timeout")
+		}
+		return
+	}
+	if err != nil {
+		// Discard the connection rather than leaking the transaction to other queries
+		discardConn(conn)
+		conn.Close()
+		return
+	}
+	releaseConn(conn)
+}
+
+// releaseConn returns conn to the pool after resetting its session, so that SET, SET ROLE,
+// temporary tables and the like do not leak to the next user of the connection
+func releaseConn(conn *sql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, "DISCARD ALL"); err != nil {
+		discardConn(conn)
+	}
+	conn.Close()
+}
+
+// discardConn makes the pool close conn instead of reusing it
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+}
+
+// Cancel stops the running statement with pg_cancel_backend and cancels its context
+func (q *CustomQuery) Cancel() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.cancelled = true
+
+	var err error
+	if q.pid != 0 {
+		_, err = q.db.CancelBackend(q.pid)
+	}
+	// Cancelling the context closes the connection, which would also end the explicit transaction
+	// or script
+	if !q.Borrowed() {
+		q.cancel()
+	}
+	return err
+}
+
+// Abort stops the query by cancelling its context even on the connection of an explicit transaction
+// or script, which closes that connection. It is meant for disconnecting, where the transaction or
+// script ends anyway.
+func (q *CustomQuery) Abort() {
+	q.mu.Lock()
+	q.cancelled = true
+	q.mu.Unlock()
+	q.cancel()
+}
+
+// Borrowed reports whether the query runs on the connection of an explicit transaction or script,
+// which outlives it
+func (q *CustomQuery) Borrowed() bool {
+	return q.tx != nil || q.session != nil
+}
+
+// isCancelled reports whether the user cancelled the query
+func (q *CustomQuery) isCancelled() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.cancelled
+}
+
+// wrapError reports user cancellation as ErrQueryCancelled instead of the driver error.
+// Any failure aborts the explicit transaction the query runs in.
+func (q *CustomQuery) wrapError(err error) error {
+	if q.tx != nil {
+		q.tx.setFailed()
+	}
+	if q.isCancelled() {
+		return ErrQueryCancelled
+	}
+	return err
+}
+
+// returnsRows reports whether the statement is a query that can be declared as a cursor
+func returnsRows(sqlQuery string) bool {
+	switch FirstKeyword(sqlQuery) {
+	case "SELECT", "WITH", "VALUES", "TABLE", "(":
+		return true
+	}
+	return false
+}
+
+// reportsAffectedRows reports whether the statement is an INSERT, UPDATE or DELETE without RETURNING,
+// which is executed for its count of affected rows rather than read as a result set
+func reportsAffectedRows(sqlQuery string) bool {
+	switch FirstKeyword(sqlQuery) {
+	case "INSERT", "UPDATE", "DELETE":
+		return !containsKeyword(scanSQL(sqlQuery), "RETURNING")
+	}
+	return false
+}
+
+// FirstKeyword returns the first keyword of a statement in upper case, skipping comments,
+// or "(" for a parenthesized query
+func FirstKeyword(sqlQuery string) string {
+	s := sqlQuery
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(s, "--"):
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				return ""
+			}
+			s = s[end+1:]
+		case strings.HasPrefix(s, "/*"):
+			s = skipBlockComment(s)
+		case strings.HasPrefix(s, "("):
+			return "("
+		default:
+			end := strings.IndexFunc(s, func(r rune) bool {
+				return !unicode.IsLetter(r) && r != '_'
+			})
+			if end < 0 {
+				end = len(s)
+			}
+			return strings.ToUpper(s[:end])
+		}
+	}
+}
+
+// skipBlockComment returns s after the (possibly nested) block comment it starts with
+func skipBlockComment(s string) string {
+	depth := 0
+	for i := 0; i < len(s)-1; i++ {
+		switch s[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return s[i+1:]
+			}
+		}
+	}
+	return ""
+}
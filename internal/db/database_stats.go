@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// GetDatabaseStat retrieves pg_stat_database counters of the current database
+func (p *PostgresDB) GetDatabaseStat() (*model.DatabaseStat, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	query := `SELECT d.datname,
+			d.xact_commit,
+			d.xact_rollback,
+			d.blks_read,
+			d.blks_hit,
+			d.deadlocks,
+			d.temp_files,
+			d.temp_bytes,
+			d.conflicts,
+			(SELECT sum(numbackends)::int FROM pg_stat_database),
+			current_setting('max_connections')::int,
+			now()
+		FROM pg_stat_database d
+		WHERE d.datname = current_database()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var stat model.DatabaseStat
+	if err := p.db.QueryRowContext(ctx, query).Scan(
+		&stat.Database,
+		&stat.Commits,
+		&stat.Rollbacks,
+		&stat.BlksRead,
+		&stat.BlksHit,
+		&stat.Deadlocks,
+		&stat.TempFiles,
+		&stat.TempBytes,
+		&stat.Conflicts,
+		&stat.Connections,
+		&stat.MaxConnections,
+		&stat.CollectedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to query database statistics: %v", err)
+	}
+
+	return &stat, nil
+}
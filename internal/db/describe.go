@@ -0,0 +1,305 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// Completion kinds accepted by GetCompletions
+const (
+	CompleteDatabases = "databases"
+	CompleteSchemas   = "schemas"
+	CompleteRoles     = "roles"
+	CompleteTables    = "tables"
+	CompleteIndexes   = "indexes"
+	CompleteViews     = "views"
+	CompleteRelations = "relations"
+	CompleteFunctions = "functions"
+)
+
+// relationKinds maps the relation describe commands and completion kinds to pg_class.relkind values
+var relationKinds = map[string]string{
+	"\\dt":            "'r', 'p'",
+	"\\di":            "'i', 'I'",
+	"\\dv":            "'v'",
+	"\\d":             "'r', 'p', 'v', 'm', 'S', 'f'",
+	CompleteTables:    "'r', 'p'",
+	CompleteIndexes:   "'i', 'I'",
+	CompleteViews:     "'v'",
+	CompleteRelations: "'r', 'p', 'v', 'm', 'S', 'f'",
+}
+
+// relationVisible restricts relations to the search path unless the pattern names a schema ($1),
+// system schemas are only listed when a name pattern ($2) is given, like psql does
+const relationVisible = `(($1::text = '' AND pg_table_is_visible(c.oid)
+		AND ($2::text <> '' OR n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_toast'))
+		OR ($1::text <> '' AND n.nspname LIKE $1::text))
+	AND ($2::text = '' OR c.relname LIKE $2::text)`
+
+// Describe runs a psql describe command (\l, \dn, \dt, \di, \dv, \df, \du or \d without
+// table) for a psql pattern and returns the result rows and column names
+func (p *PostgresDB) Describe(command, pattern string) ([][]interface{}, []string, error) {
+	if p.db == nil {
+		return nil, nil, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, name := splitPattern(pattern)
+	var query string
+	args := []interface{}{schema, name}
+
+	switch command {
+	case "\\l":
+		query = `SELECT d.datname AS "Name",
+				pg_get_userbyid(d.datdba) AS "Owner",
+				pg_encoding_to_char(d.encoding) AS "Encoding",
+				d.datcollate AS "Collate",
+				d.datctype AS "Ctype",
+				CASE WHEN has_database_privilege(d.datname, 'CONNECT')
+					THEN pg_size_pretty(pg_database_size(d.datname)) ELSE 'No Access' END AS "Size",
+				shobj_description(d.oid, 'pg_database') AS "Description"
+			FROM pg_database d
+			WHERE $1::text = '' OR d.datname LIKE $1::text
+			ORDER BY 1`
+		args = []interface{}{likePart(pattern)}
+
+	case "\\dn":
+		query = `SELECT n.nspname AS "Name",
+				pg_get_userbyid(n.nspowner) AS "Owner",
+				obj_description(n.oid, 'pg_namespace') AS "Description"
+			FROM pg_namespace n
+			WHERE ($1::text = '' AND n.nspname !~ '^pg_' AND n.nspname <> 'information_schema')
+				OR ($1::text <> '' AND n.nspname LIKE $1::text)
+			ORDER BY 1`
+		args = []interface{}{likePart(pattern)}
+
+	case "\\du":
+		query = `SELECT r.rolname AS "Role name",
+				concat_ws(', ',
+					CASE WHEN r.rolsuper THEN 'Superuser' END,
+					CASE WHEN r.rolcreaterole THEN 'Create role' END,
+					CASE WHEN r.rolcreatedb THEN 'Create DB' END,
+					CASE WHEN NOT r.rolcanlogin THEN 'Cannot login' END,
+					CASE WHEN r.rolreplication THEN 'Replication' END,
+					CASE WHEN r.rolbypassrls THEN 'Bypass RLS' END,
+					CASE WHEN r.rolconnlimit >= 0 THEN r.rolconnlimit || ' connections' END,
+					CASE WHEN r.rolvaliduntil IS NOT NULL THEN 'Password valid until ' || r.rolvaliduntil END) AS "Attributes",
+				array_to_string(ARRAY(
+					SELECT b.rolname FROM pg_auth_members m JOIN pg_roles b ON m.roleid = b.oid
+					WHERE m.member = r.oid ORDER BY 1), ', ') AS "Member of"
+			FROM pg_roles r
+			WHERE ($1::text = '' AND r.rolname !~ '^pg_') OR ($1::text <> '' AND r.rolname LIKE $1::text)
+			ORDER BY 1`
+		args = []interface{}{likePart(pattern)}
+
+	case "\\df":
+		// prokind replaced proisagg and proiswindow in PostgreSQL 11
+		kind := `CASE WHEN p.proisagg THEN 'agg' WHEN p.proiswindow THEN 'window'
+				WHEN p.prorettype = 'trigger'::regtype THEN 'trigger' ELSE 'func' END`
+		if versionNum >= 110000 {
+			kind = `CASE p.prokind WHEN 'a' THEN 'agg' WHEN 'w' THEN 'window' WHEN 'p' THEN 'proc'
+				ELSE CASE WHEN p.prorettype = 'trigger'::regtype THEN 'trigger' ELSE 'func' END END`
+		}
+		query = fmt.Sprintf(`SELECT n.nspname AS "Schema",
+				p.proname AS "Name",
+				pg_get_function_result(p.oid) AS "Result data type",
+				pg_get_function_arguments(p.oid) AS "Argument data types",
+				%s AS "Type"
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE (($1::text = '' AND pg_function_is_visible(p.oid)
+					AND ($2::text <> '' OR n.nspname NOT IN ('pg_catalog', 'information_schema')))
+				OR ($1::text <> '' AND n.nspname LIKE $1::text))
+				AND ($2::text = '' OR p.proname LIKE $2::text)
+			ORDER BY 1, 2, 4`, kind)
+
+	case "\\dt", "\\di", "\\dv", "\\d":
+		table := ""
+		if command == "\\di" {
+			table = `(SELECT t.relname FROM pg_index i JOIN pg_class t ON t.oid = i.indrelid WHERE i.indexrelid = c.oid) AS "Table",`
+		}
+		query = fmt.Sprintf(`SELECT n.nspname AS "Schema",
+				c.relname AS "Name",
+				CASE c.relkind
+					WHEN 'r' THEN 'table' WHEN 'p' THEN 'partitioned table' WHEN 'v' THEN 'view'
+					WHEN 'm' THEN 'materialized view' WHEN 'S' THEN 'sequence' WHEN 'f' THEN 'foreign table'
+					WHEN 'i' THEN 'index' WHEN 'I' THEN 'partitioned index'
+				END AS "Type",
+				pg_get_userbyid(c.relowner) AS "Owner",
+				%s
+				pg_size_pretty(pg_total_relation_size(c.oid)) AS "Size",
+				obj_description(c.oid, 'pg_class') AS "Description"
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN (%s) AND %s
+			ORDER BY 1, 2`, table, relationKinds[command], relationVisible)
+
+	default:
+		return nil, nil, fmt.Errorf("unknown command %s", command)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to run %s: %v", command, err)
+	}
+	defer rows.Close()
+
+	return scanResults(rows)
+}
+
+// FindRelations retrieves the tables, views, materialized views, sequences and foreign tables matching a psql pattern
+func (p *PostgresDB) FindRelations(pattern string) ([]model.SchemaObject, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	schema, name := splitPattern(pattern)
+	rows, err := p.db.QueryContext(ctx, `SELECT c.oid::bigint, n.nspname, c.relname, c.relkind::text
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN (`+relationKinds["\\d"]+`) AND `+relationVisible+`
+		ORDER BY 2, 3`, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relations: %v", err)
+	}
+	defer rows.Close()
+
+	var relations []model.SchemaObject
+	for rows.Next() {
+		var relation model.SchemaObject
+		if err := rows.Scan(&relation.OID, &relation.Schema, &relation.Name, &relation.Kind); err != nil {
+			return nil, fmt.Errorf("failed to parse relations: %v", err)
+		}
+		relations = append(relations, relation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate relations: %v", err)
+	}
+
+	return relations, nil
+}
+
+// GetCompletions retrieves names of the given kind starting with prefix, schema qualified when prefix contains a dot
+func (p *PostgresDB) GetCompletions(kind, prefix string) ([]string, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	like := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+
+	var query string
+	switch kind {
+	case CompleteDatabases:
+		query = `SELECT datname FROM pg_database WHERE datallowconn AND datname LIKE $1 ORDER BY 1 LIMIT 200`
+	case CompleteSchemas:
+		query = `SELECT nspname FROM pg_namespace WHERE nspname LIKE $1 ORDER BY 1 LIMIT 200`
+	case CompleteRoles:
+		query = `SELECT rolname FROM pg_roles WHERE rolname LIKE $1 ORDER BY 1 LIMIT 200`
+	case CompleteFunctions:
+		query = `SELECT DISTINCT name FROM (
+				SELECT CASE WHEN strpos($1, '.') > 0 THEN n.nspname || '.' || p.proname ELSE p.proname END AS name
+				FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+				WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+			) f WHERE name LIKE $1 ORDER BY 1 LIMIT 200`
+	default:
+		relkinds, ok := relationKinds[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown completion kind %s", kind)
+		}
+		query = `SELECT DISTINCT name FROM (
+				SELECT CASE WHEN strpos($1, '.') > 0 THEN n.nspname || '.' || c.relname ELSE c.relname END AS name
+				FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+				WHERE c.relkind IN (` + relkinds + `) AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_toast'
+			) r WHERE name LIKE $1 ORDER BY 1 LIMIT 200`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query, like)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completions: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to parse completions: %v", err)
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate completions: %v", err)
+	}
+
+	return names, nil
+}
+
+// splitPattern converts a psql pattern "[schema.]name" to LIKE patterns, empty parts match everything
+func splitPattern(pattern string) (string, string) {
+	inQuotes := false
+	for i, r := range pattern {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '.' && !inQuotes:
+			return likePart(pattern[:i]), likePart(pattern[i+1:])
+		}
+	}
+	return "", likePart(pattern)
+}
+
+// likePart converts one part of a psql pattern to a LIKE pattern: unquoted text is folded to
+// lower case, * and ? are wildcards outside double quotes
+func likePart(part string) string {
+	if part == "" || part == "*" {
+		return ""
+	}
+
+	var b strings.Builder
+	inQuotes := false
+	runes := []rune(part)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '"':
+			// A doubled quote inside quotes stands for a literal quote
+			if inQuotes && i+1 < len(runes) && runes[i+1] == '"' {
+				b.WriteRune('"')
+				i++
+			} else {
+				inQuotes = !inQuotes
+			}
+		case r == '\\' || r == '%' || r == '_':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '*' && !inQuotes:
+			b.WriteRune('%')
+		case r == '?' && !inQuotes:
+			b.WriteRune('_')
+		case inQuotes:
+			b.WriteRune(r)
+		default:
+			b.WriteString(strings.ToLower(string(r)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// GetIndexReport retrieves index usage of the current database and flags unused, redundant and
+// invalid indexes as well as foreign keys without a supporting index
+func (p *PostgresDB) GetIndexReport() (*model.IndexReport, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report := &model.IndexReport{}
+
+	rows, err := p.db.QueryContext(ctx, `SELECT s.schemaname,
+			s.relname,
+			s.indexrelname,
+			s.idx_scan,
+			pg_relation_size(s.indexrelid),
+			i.indisunique,
+			i.indisprimary,
+			i.indisvalid,
+			am.amname,
+			i.indkey::text,
+			coalesce(pg_get_expr(i.indexprs, i.indrelid), ''),
+			coalesce(pg_get_expr(i.indpred, i.indrelid), ''),
+			pg_get_indexdef(s.indexrelid)
+		FROM pg_stat_user_indexes s
+		JOIN pg_index i ON i.indexrelid = s.indexrelid
+		JOIN pg_class c ON c.oid = s.indexrelid
+		JOIN pg_am am ON am.oid = c.relam
+		ORDER BY s.schemaname, s.relname, s.indexrelname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index usage: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index model.IndexUsage
+		if err := rows.Scan(
+			&index.Schema,
+			&index.Table,
+			&index.Index,
+			&index.Scans,
+			&index.Size,
+			&index.Unique,
+			&index.Primary,
+			&index.Valid,
+			&index.Method,
+			&index.Columns,
+			&index.Expressions,
+			&index.Predicate,
+			&index.Definition,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse index usage: %v", err)
+		}
+		report.Indexes = append(report.Indexes, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate index usage: %v", err)
+	}
+
+	flagIndexIssues(report.Indexes)
+
+	// A foreign key is supported when its columns, in any order, are the leading columns of a valid index
+	fkRows, err := p.db.QueryContext(ctx, `SELECT n.nspname,
+			t.relname,
+			c.conname,
+			array_to_string(ARRAY(
+				SELECT a.attname FROM unnest(c.conkey) WITH ORDINALITY k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord), ', '),
+			pg_get_constraintdef(c.oid)
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE c.contype = 'f'
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_index i
+				WHERE i.indrelid = c.conrelid
+					AND i.indisvalid
+					AND i.indpred IS NULL
+					AND (i.indkey::smallint[])[0:cardinality(c.conkey) - 1] @> c.conkey)
+		ORDER BY n.nspname, t.relname, c.conname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %v", err)
+	}
+	defer fkRows.Close()
+
+	for fkRows.Next() {
+		var fk model.UnindexedForeignKey
+		if err := fkRows.Scan(&fk.Schema, &fk.Table, &fk.Constraint, &fk.Columns, &fk.Definition); err != nil {
+			return nil, fmt.Errorf("failed to parse foreign keys: %v", err)
+		}
+		report.UnindexedForeignKeys = append(report.UnindexedForeignKeys, fk)
+	}
+
+	if err := fkRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate foreign keys: %v", err)
+	}
+
+	// Indexes with issues first, then the largest ones
+	sort.SliceStable(report.Indexes, func(i, j int) bool {
+		a, b := report.Indexes[i], report.Indexes[j]
+		if (len(a.Issues) > 0) != (len(b.Issues) > 0) {
+			return len(a.Issues) > 0
+		}
+		return a.Size > b.Size
+	})
+
+	return report, nil
+}
+
+// flagIndexIssues records invalid, unused, duplicate and prefix-redundant indexes
+func flagIndexIssues(indexes []model.IndexUsage) {
+	byTable := make(map[string][]int)
+	for i, index := range indexes {
+		key := index.Schema + "." + index.Table
+		byTable[key] = append(byTable[key], i)
+	}
+
+	for i := range indexes {
+		index := &indexes[i]
+
+		if !index.Valid {
+			index.Issues = append(index.Issues, "invalid")
+		}
+		// Unique and primary key indexes enforce constraints even when never scanned
+		if index.Scans == 0 && !index.Unique && !index.Primary {
+			index.Issues = append(index.Issues, "unused")
+		}
+
+		for _, j := range byTable[index.Schema+"."+index.Table] {
+			other := &indexes[j]
+			if i == j {
+				continue
+			}
+			if !other.Valid || other.Method != index.Method || other.Expressions != index.Expressions || other.Predicate != index.Predicate {
+				continue
+			}
+
+			switch {
+			case other.Columns == index.Columns:
+				// Flag only one side of a duplicate pair, keeping the constraint index or the first by name
+				if keepIndex(*index, *other, i < j) {
+					continue
+				}
+				index.Issues = append(index.Issues, "duplicate of "+other.Index)
+			case index.Method == "btree" && !index.Unique && strings.HasPrefix(other.Columns, index.Columns+" "):
+				index.Issues = append(index.Issues, "redundant, prefix of "+other.Index)
+			}
+		}
+	}
+}
+
+// keepIndex reports whether index should be kept over its duplicate other
+func keepIndex(index, other model.IndexUsage, first bool) bool {
+	if index.Primary != other.Primary {
+		return index.Primary
+	}
+	if index.Unique != other.Unique {
+		return index.Unique
+	}
+	return first
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"p6s/internal/model"
+)
+
+// GetLockTree retrieves blocked sessions and their blockers as an ordered blocker -> waiter tree
+func (p *PostgresDB) GetLockTree() ([]model.LockNode, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
+	if err != nil {
+		return nil, err
+	}
+
+	// pg_locks.waitstart is only available since PostgreSQL 14
+	waitStart := "a.state_change"
+	if versionNum >= 140000 {
+		waitStart = "COALESCE(l.waitstart, a.state_change)"
+	}
+
+	query := fmt.Sprintf(`WITH waiting AS (
+			SELECT pid, pg_blocking_pids(pid) AS blockers
+			FROM pg_stat_activity
+			WHERE cardinality(pg_blocking_pids(pid)) > 0
+		), involved AS (
+			SELECT pid FROM waiting
+			UNION
+			SELECT unnest(blockers) FROM waiting
+		)
+		SELECT a.pid,
+			COALESCE(w.blockers, '{}'::int[]),
+			a.usename,
+			a.datname,
+			a.state,
+			l.locktype,
+			l.mode,
+			CASE WHEN l.relation IS NOT NULL THEN l.relation::regclass::text END,
+			CASE WHEN l.pid IS NOT NULL THEN EXTRACT(EPOCH FROM now() - %s)::float8 END,
+			a.query
+		FROM involved i
+		JOIN pg_stat_activity a ON a.pid = i.pid
+		LEFT JOIN waiting w ON w.pid = a.pid
+		LEFT JOIN LATERAL (
+			SELECT * FROM pg_locks pl
+			WHERE pl.pid = a.pid AND NOT pl.granted
+			LIMIT 1
+		) l ON true
+		ORDER BY a.backend_start`, waitStart)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lock tree: %v", err)
+	}
+	defer rows.Close()
+
+	var nodes []model.LockNode
+	for rows.Next() {
+		var node model.LockNode
+		var blockers pq.Int64Array
+		var waitSeconds sql.NullFloat64
+		if err := rows.Scan(
+			&node.PID,
+			&blockers,
+			&node.User,
+			&node.Database,
+			&node.State,
+			&node.LockType,
+			&node.LockMode,
+			&node.Relation,
+			&waitSeconds,
+			&node.Query,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse lock tree: %v", err)
+		}
+		for _, pid := range blockers {
+			node.BlockedBy = append(node.BlockedBy, int(pid))
+		}
+		if waitSeconds.Valid {
+			node.WaitDuration = time.Duration(waitSeconds.Float64 * float64(time.Second))
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate lock tree: %v", err)
+	}
+
+	return buildLockTree(nodes), nil
+}
+
+// buildLockTree orders nodes depth-first from root blockers down to their waiters.
+// A waiter blocked by several sessions is listed under each of them.
+func buildLockTree(nodes []model.LockNode) []model.LockNode {
+	byPID := make(map[int]model.LockNode, len(nodes))
+	waiters := make(map[int][]int)
+	for _, node := range nodes {
+		byPID[node.PID] = node
+	}
+	for _, node := range nodes {
+		for _, blocker := range node.BlockedBy {
+			if _, ok := byPID[blocker]; ok {
+				waiters[blocker] = append(waiters[blocker], node.PID)
+			}
+		}
+	}
+
+	var tree []model.LockNode
+	emitted := make(map[int]bool)
+	onPath := make(map[int]bool)
+
+	var walk func(pid, root, depth int)
+	walk = func(pid, root, depth int) {
+		// Deadlocked sessions block each other until the deadlock detector fires
+		if onPath[pid] {
+			return
+		}
+		onPath[pid] = true
+		emitted[pid] = true
+
+		node := byPID[pid]
+		node.RootPID = root
+		node.Depth = depth
+		tree = append(tree, node)
+
+		for _, waiter := range waiters[pid] {
+			walk(waiter, root, depth+1)
+		}
+		onPath[pid] = false
+	}
+
+	for _, node := range nodes {
+		isRoot := true
+		for _, blocker := range node.BlockedBy {
+			if _, ok := byPID[blocker]; ok {
+				isRoot = false
+				break
+			}
+		}
+		if isRoot {
+			walk(node.PID, node.PID, 0)
+		}
+	}
+
+	// Sessions only reachable through a cycle have no root blocker
+	for _, node := range nodes {
+		if !emitted[node.PID] {
+			walk(node.PID, node.PID, 0)
+		}
+	}
+
+	return tree
+}
+
+// GetLocks retrieves pg_locks with relation names and session info, optionally filtered by PID (0 means all)
+func (p *PostgresDB) GetLocks(pid int) ([]model.Lock, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	query := `SELECT l.pid,
+			l.locktype,
+			l.mode,
+			l.granted,
+			CASE WHEN l.relation IS NOT NULL
+				THEN COALESCE(quote_ident(n.nspname) || '.' || quote_ident(c.relname), l.relation::text)
+			END,
+			a.usename,
+			EXTRACT(EPOCH FROM now() - a.query_start)::float8,
+			a.state,
+			a.query
+		FROM pg_locks l
+		LEFT JOIN pg_class c ON c.oid = l.relation
+		LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.pid IS DISTINCT FROM pg_backend_pid()
+		AND ($1::int = 0 OR l.pid = $1::int)
+		ORDER BY l.granted, l.pid, l.locktype, l.mode`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query, pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query locks: %v", err)
+	}
+	defer rows.Close()
+
+	var locks []model.Lock
+	for rows.Next() {
+		var lock model.Lock
+		if err := rows.Scan(
+			&lock.PID,
+			&lock.LockType,
+			&lock.Mode,
+			&lock.Granted,
+			&lock.Relation,
+			&lock.User,
+			&lock.QueryAge,
+			&lock.State,
+			&lock.Query,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse locks: %v", err)
+		}
+		locks = append(locks, lock)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate locks: %v", err)
+	}
+
+	return locks, nil
+}
+
+// GetBlockingPIDs retrieves the PIDs blocking the specified backend
+func (p *PostgresDB) GetBlockingPIDs(pid int) ([]int, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var blockers pq.Int64Array
+	if err := p.db.QueryRowContext(ctx, "SELECT pg_blocking_pids($1)", pid).Scan(&blockers); err != nil {
+		return nil, fmt.Errorf("failed to get blocking PIDs of %d: %v", pid, err)
+	}
+
+	pids := make([]int, len(blockers))
+	for i, blocker := range blockers {
+		pids[i] = int(blocker)
+	}
+
+	return pids, nil
+}
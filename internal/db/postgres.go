@@ -4,15 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"p6s/internal/model"
 )
 
 // PostgresDB wraps PostgreSQL database connection and operations
 type PostgresDB struct {
 	db *sql.DB
+	// tx is the explicit transaction custom queries run in, nil when none is open
+	tx *transaction
+	// session is the connection of the running script, nil when none runs
+	session *session
+
+	// backends are the PIDs of the connections reserved for custom queries, transactions and
+	// scripts, which the long transaction view leaves out
+	backendsMu sync.Mutex
+	backends   map[int]bool
 }
 
 // NewPostgresDB creates a new PostgresDB instance
@@ -49,8 +59,41 @@ func (p *PostgresDB) Connect(connStr string) error {
 	return nil
 }
 
+// addBackend records the PID of a reserved connection
+func (p *PostgresDB) addBackend(pid int) {
+	p.backendsMu.Lock()
+	defer p.backendsMu.Unlock()
+	if p.backends == nil {
+		p.backends = map[int]bool{}
+	}
+	p.backends[pid] = true
+}
+
+// removeBackend forgets the PID of a connection returned to the pool
+func (p *PostgresDB) removeBackend(pid int) {
+	p.backendsMu.Lock()
+	defer p.backendsMu.Unlock()
+	delete(p.backends, pid)
+}
+
+// ownBackends returns the PIDs of the reserved connections
+func (p *PostgresDB) ownBackends() pq.Int64Array {
+	p.backendsMu.Lock()
+	defer p.backendsMu.Unlock()
+	pids := pq.Int64Array{}
+	for pid := range p.backends {
+		pids = append(pids, int64(pid))
+	}
+	return pids
+}
+
 // Close closes the database connection
 func (p *PostgresDB) Close() error {
+	// An open transaction is rolled back rather than left to the server to notice the disconnect
+	if p.tx != nil {
+		p.Rollback("")
+	}
+	p.EndSession()
 	if p.db != nil {
 		return p.db.Close()
 	}
@@ -62,6 +105,39 @@ func (p *PostgresDB) IsConnected() bool {
 	return p.db != nil
 }
 
+// connectionColumns is the pg_stat_activity column list scanned by scanConnection, formatted with the table alias
+const connectionColumns = `%[1]s.pid, %[1]s.usename, %[1]s.datname, %[1]s.client_addr, %[1]s.application_name, %[1]s.backend_start, %[1]s.state, %[1]s.query,
+				%[1]s.xact_start, %[1]s.query_start, %[1]s.state_change, %[1]s.wait_event_type, %[1]s.wait_event, %[1]s.backend_type, %[1]s.backend_xid::text, %[1]s.backend_xmin::text`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanConnection scans a row selected with connectionColumns
+func scanConnection(row rowScanner) (model.Connection, error) {
+	var conn model.Connection
+	err := row.Scan(
+		&conn.PID,
+		&conn.User,
+		&conn.Database,
+		&conn.ClientAddr,
+		&conn.ApplicationName,
+		&conn.BackendStart,
+		&conn.State,
+		&conn.Query,
+		&conn.XactStart,
+		&conn.QueryStart,
+		&conn.StateChange,
+		&conn.WaitEventType,
+		&conn.WaitEvent,
+		&conn.BackendType,
+		&conn.BackendXid,
+		&conn.BackendXmin,
+	)
+	return conn, err
+}
+
 // GetConnections retrieves current database connection information
 func (p *PostgresDB) GetConnections(filterType string) ([]model.Connection, error) {
 	if p.db == nil {
@@ -71,24 +147,17 @@ func (p *PostgresDB) GetConnections(filterType string) ([]model.Connection, erro
 	var query string
 	switch filterType {
 	case "all":
-		query = `SELECT pid, usename, datname, client_addr, application_name, backend_start, state, query 
-				FROM pg_stat_activity 
-				WHERE pid <> pg_backend_pid() 
-				ORDER BY backend_start DESC`
+		query = `SELECT ` + fmt.Sprintf(connectionColumns, "a") + ` 
+				FROM pg_stat_activity a 
+				WHERE a.pid <> pg_backend_pid() 
+				ORDER BY a.backend_start DESC`
 	case "active":
-		query = `SELECT pid, usename, datname, client_addr, application_name, backend_start, state, query 
-				FROM pg_stat_activity 
-				WHERE pid <> pg_backend_pid() AND state = 'active' 
-				ORDER BY backend_start DESC`
+		query = `SELECT ` + fmt.Sprintf(connectionColumns, "a") + ` 
+				FROM pg_stat_activity a 
+				WHERE a.pid <> pg_backend_pid() AND a.state = 'active' 
+				ORDER BY a.backend_start DESC`
 	case "blocked":
-		query = `SELECT blocked_activity.pid, 
-					blocked_activity.usename, 
-					blocked_activity.datname, 
-					blocked_activity.client_addr, 
-					blocked_activity.application_name, 
-					blocked_activity.backend_start, 
-					blocked_activity.state, 
-					blocked_activity.query 
+		query = `SELECT ` + fmt.Sprintf(connectionColumns, "blocked_activity") + ` 
 				FROM pg_stat_activity blocked_activity 
 				JOIN pg_locks blocked_locks ON blocked_activity.pid = blocked_locks.pid 
 				JOIN pg_locks blocking_locks ON blocked_locks.transactionid = blocking_locks.transactionid AND blocked_locks.pid != blocking_locks.pid 
@@ -112,17 +181,8 @@ func (p *PostgresDB) GetConnections(filterType string) ([]model.Connection, erro
 
 	var connections []model.Connection
 	for rows.Next() {
-		var conn model.Connection
-		if err := rows.Scan(
-			&conn.PID,
-			&conn.User,
-			&conn.Database,
-			&conn.ClientAddr,
-			&conn.ApplicationName,
-			&conn.BackendStart,
-			&conn.State,
-			&conn.Query,
-		); err != nil {
+		conn, err := scanConnection(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to parse connection info: %v", err)
 		}
 		connections = append(connections, conn)
@@ -135,58 +195,188 @@ func (p *PostgresDB) GetConnections(filterType string) ([]model.Connection, erro
 	return connections, nil
 }
 
-// GetTableStats retrieves table size statistics
-func (p *PostgresDB) GetTableStats() ([]model.TableStat, error) {
+// GetSession retrieves pg_stat_activity information of a single backend
+func (p *PostgresDB) GetSession(pid int) (*model.Connection, error) {
 	if p.db == nil {
 		return nil, fmt.Errorf("database not connected")
 	}
 
-	query := `SELECT 
-			n.nspname as schema,
-			c.relname as name,
-			pg_size_pretty(pg_total_relation_size(c.oid)) as total_size,
-			pg_size_pretty(pg_relation_size(c.oid)) as table_size,
-			pg_size_pretty(pg_indexes_size(c.oid)) as index_size,
-			c.reltuples::bigint as row_count
-		FROM pg_class c
-		LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
-		WHERE c.relkind = 'r'
-		AND n.nspname NOT IN ('pg_catalog', 'information_schema')
-		ORDER BY pg_total_relation_size(c.oid) DESC
-		LIMIT 100`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + fmt.Sprintf(connectionColumns, "a") + ` FROM pg_stat_activity a WHERE a.pid = $1`
+
+	conn, err := scanConnection(p.db.QueryRowContext(ctx, query, pid))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("backend %d no longer exists", pid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session %d: %v", pid, err)
+	}
+
+	return &conn, nil
+}
+
+// CancelBackend cancels the current query of the specified backend
+func (p *PostgresDB) CancelBackend(pid int) (bool, error) {
+	if p.db == nil {
+		return false, fmt.Errorf("database not connected")
+	}
 
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	var ok bool
+	if err := p.db.QueryRowContext(ctx, "SELECT pg_cancel_backend($1)", pid).Scan(&ok); err != nil {
+		return false, fmt.Errorf("failed to cancel backend %d: %v", pid, err)
+	}
 
-	rows, err := p.db.QueryContext(ctx, query)
+	return ok, nil
+}
+
+// TerminateBackend terminates the specified backend and its session
+func (p *PostgresDB) TerminateBackend(pid int) (bool, error) {
+	if p.db == nil {
+		return false, fmt.Errorf("database not connected")
+	}
+
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ok bool
+	if err := p.db.QueryRowContext(ctx, "SELECT pg_terminate_backend($1)", pid).Scan(&ok); err != nil {
+		return false, fmt.Errorf("failed to terminate backend %d: %v", pid, err)
+	}
+
+	return ok, nil
+}
+
+// Sort columns accepted by GetTableStats
+const (
+	TableSortSchema    = "schema"
+	TableSortName      = "name"
+	TableSortKind      = "kind"
+	TableSortTotalSize = "total_size"
+	TableSortTableSize = "table_size"
+	TableSortIndexSize = "index_size"
+	TableSortRows      = "row_count"
+)
+
+// TableStatsPageSize is the number of relations returned per page by GetTableStats
+const TableStatsPageSize = 100
+
+// TableStatsFilter selects, orders and pages the relations returned by GetTableStats.
+// Schema and Name are ILIKE patterns, empty matches everything.
+type TableStatsFilter struct {
+	Schema     string
+	Name       string
+	SortBy     string
+	Descending bool
+	Page       int
+}
+
+// GetTableStats retrieves size statistics of tables, partitioned tables and materialized views,
+// returning one page of relations and the number of matching relations
+func (p *PostgresDB) GetTableStats(filter TableStatsFilter) ([]model.TableStat, int, error) {
+	if p.db == nil {
+		return nil, 0, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query table statistics: %v", err)
+		return nil, 0, err
+	}
+
+	// Partitioned tables have no storage of their own, their sizes are summed over the partition tree since PostgreSQL 12
+	totalSize, tableSize, indexSize := "pg_total_relation_size(c.oid)", "pg_relation_size(c.oid)", "pg_indexes_size(c.oid)"
+	if versionNum >= 120000 {
+		partitionSum := "CASE WHEN c.relkind = 'p' THEN (SELECT coalesce(sum(%[1]s(t.relid)), 0) FROM pg_partition_tree(c.oid) t) ELSE %[1]s(c.oid) END"
+		totalSize = fmt.Sprintf(partitionSum, "pg_total_relation_size")
+		tableSize = fmt.Sprintf(partitionSum, "pg_relation_size")
+		indexSize = fmt.Sprintf(partitionSum, "pg_indexes_size")
+	}
+
+	sortColumns := map[string]string{
+		TableSortSchema:    "schema",
+		TableSortName:      "name",
+		TableSortKind:      "kind",
+		TableSortTotalSize: "total_bytes",
+		TableSortTableSize: "table_bytes",
+		TableSortIndexSize: "index_bytes",
+		TableSortRows:      "row_count",
+	}
+	sortColumn, ok := sortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = "total_bytes"
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	query := fmt.Sprintf(`SELECT schema, name, kind,
+			pg_size_pretty(total_bytes), pg_size_pretty(table_bytes), pg_size_pretty(index_bytes),
+			total_bytes, table_bytes, index_bytes, row_count,
+			count(*) OVER ()
+		FROM (
+			SELECT n.nspname AS schema,
+				c.relname AS name,
+				c.relkind::text AS kind,
+				%s::bigint AS total_bytes,
+				%s::bigint AS table_bytes,
+				%s::bigint AS index_bytes,
+				greatest(c.reltuples, 0)::bigint AS row_count
+			FROM pg_class c
+			LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN ('r', 'p', 'm')
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND ($1::text = '' OR n.nspname ILIKE $1::text)
+			AND ($2::text = '' OR c.relname ILIKE $2::text)
+		) t
+		ORDER BY %s %s, schema, name
+		LIMIT $3 OFFSET $4`, totalSize, tableSize, indexSize, sortColumn, direction)
+
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+
+	rows, err := p.db.QueryContext(ctx, query, filter.Schema, filter.Name, TableStatsPageSize, filter.Page*TableStatsPageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query table statistics: %v", err)
 	}
 	defer rows.Close()
 
 	var tableStats []model.TableStat
+	total := 0
 	for rows.Next() {
 		var stat model.TableStat
 		if err := rows.Scan(
 			&stat.Schema,
 			&stat.Name,
+			&stat.Kind,
 			&stat.TotalSize,
 			&stat.TableSize,
 			&stat.IndexSize,
+			&stat.TotalBytes,
+			&stat.TableBytes,
+			&stat.IndexBytes,
 			&stat.RowCount,
+			&total,
 		); err != nil {
-			return nil, fmt.Errorf("failed to parse table statistics: %v", err)
+			return nil, 0, fmt.Errorf("failed to parse table statistics: %v", err)
 		}
 		tableStats = append(tableStats, stat)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate table statistics: %v", err)
+		return nil, 0, fmt.Errorf("failed to iterate table statistics: %v", err)
 	}
 
-	return tableStats, nil
+	return tableStats, total, nil
 }
 
 // GetDatabaseVersion retrieves database version information
@@ -203,6 +393,24 @@ func (p *PostgresDB) GetDatabaseVersion() (string, error) {
 	return version, nil
 }
 
+// GetServerVersionNum retrieves server version as number, e.g. 130004
+func (p *PostgresDB) GetServerVersionNum() (int, error) {
+	if p.db == nil {
+		return 0, fmt.Errorf("database not connected")
+	}
+
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var versionNum int
+	if err := p.db.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int").Scan(&versionNum); err != nil {
+		return 0, fmt.Errorf("failed to get server version number: %v", err)
+	}
+
+	return versionNum, nil
+}
+
 // GetCurrentDatabase retrieves current database name
 func (p *PostgresDB) GetCurrentDatabase() (string, error) {
 	if p.db == nil {
@@ -251,24 +459,22 @@ func (p *PostgresDB) GetDatabases() ([]string, error) {
 	return databases, nil
 }
 
-// ExecuteCustomQuery executes custom SQL query
-func (p *PostgresDB) ExecuteCustomQuery(sqlQuery string) ([][]interface{}, []string, error) {
-	if p.db == nil {
-		return nil, nil, fmt.Errorf("database not connected")
+// scanResults reads all rows of a result set as typed values together with the column names
+func scanResults(rows *sql.Rows) ([][]interface{}, []string, error) {
+	columns, columnTypes, err := resultColumns(rows)
+	if err != nil {
+		return nil, nil, err
 	}
 
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-
-	rows, err := p.db.QueryContext(ctx, sqlQuery)
+	results, err := scanRows(rows, columnTypes, 0)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to execute SQL query: %v", err)
+		return nil, nil, err
 	}
-	defer rows.Close()
-
+	return results, columns, nil
+}
 
+// resultColumns returns the column names and types of a result set
+func resultColumns(rows *sql.Rows) ([]string, []*sql.ColumnType, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get column info: %v", err)
@@ -280,57 +486,31 @@ func (p *PostgresDB) ExecuteCustomQuery(sqlQuery string) ([][]interface{}, []str
 		return nil, nil, fmt.Errorf("failed to get column types: %v", err)
 	}
 
+	return columns, columnTypes, nil
+}
 
+// scanRows reads up to limit rows of a result set as model.Value, all rows when limit is 0
+func scanRows(rows *sql.Rows, columnTypes []*sql.ColumnType, limit int) ([][]interface{}, error) {
 	var results [][]interface{}
 
 
-	for rows.Next() {
+	for (limit == 0 || len(results) < limit) && rows.Next() {
 
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
+		values := make([]interface{}, len(columnTypes))
+		valuePtrs := make([]interface{}, len(columnTypes))
 		for i := range values {
 			valuePtrs[i] = &values[i]
 		}
 
 
 		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, nil, fmt.Errorf("failed to scan row data: %v", err)
+			return nil, fmt.Errorf("failed to scan row data: %v", err)
 		}
 
 
-		row := make([]interface{}, len(columns))
+		row := make([]interface{}, len(columnTypes))
 		for i, val := range values {
-			if val == nil {
-				row[i] = "NULL"
-			} else {
-		
-				switch columnTypes[i].DatabaseTypeName() {
-				case "VARCHAR", "TEXT", "CHAR":
-					if b, ok := val.([]byte); ok {
-						row[i] = string(b)
-					} else {
-						row[i] = fmt.Sprintf("%v", val)
-					}
-				case "INT4", "INT8", "BIGINT", "INTEGER":
-					row[i] = fmt.Sprintf("%v", val)
-				case "FLOAT4", "FLOAT8", "NUMERIC", "DECIMAL":
-					row[i] = fmt.Sprintf("%v", val)
-				case "BOOL":
-					row[i] = fmt.Sprintf("%v", val)
-				case "TIMESTAMP", "TIMESTAMPTZ", "DATE", "TIME":
-					if t, ok := val.(time.Time); ok {
-						row[i] = t.Format("2006-01-02 15:04:05")
-					} else {
-						row[i] = fmt.Sprintf("%v", val)
-					}
-				default:
-					if b, ok := val.([]byte); ok {
-						row[i] = string(b)
-					} else {
-						row[i] = fmt.Sprintf("%v", val)
-					}
-				}
-			}
+			row[i] = model.Value{Type: columnTypes[i].DatabaseTypeName(), Data: val}
 		}
 
 		results = append(results, row)
@@ -338,8 +518,8 @@ func (p *PostgresDB) ExecuteCustomQuery(sqlQuery string) ([][]interface{}, []str
 
 
 	if err := rows.Err(); err != nil {
-		return nil, nil, fmt.Errorf("failed to iterate results: %v", err)
+		return nil, fmt.Errorf("failed to iterate results: %v", err)
 	}
 
-	return results, columns, nil
+	return results, nil
 }
\ No newline at end of file
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// currentLSN is the latest known WAL position on both primaries and standbys
+const currentLSN = `CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END`
+
+// GetReplicationStatus retrieves replicas, replication slots and standby recovery progress
+func (p *PostgresDB) GetReplicationStatus() (*model.ReplicationStatus, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status := &model.ReplicationStatus{}
+
+	recovery := &status.Recovery
+	if err := p.db.QueryRowContext(ctx, `SELECT pg_is_in_recovery(),
+			(SELECT status FROM pg_stat_wal_receiver LIMIT 1),
+			CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn()::text END,
+			CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn()::text END,
+			CASE WHEN pg_is_in_recovery() THEN pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn())::bigint END,
+			CASE WHEN pg_is_in_recovery() THEN EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8 END`).Scan(
+		&recovery.InRecovery,
+		&recovery.ReceiverStatus,
+		&recovery.ReceiveLSN,
+		&recovery.ReplayLSN,
+		&recovery.LagBytes,
+		&recovery.ReplayDelay,
+	); err != nil {
+		return nil, fmt.Errorf("failed to query recovery status: %v", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, `SELECT pid,
+			application_name,
+			client_addr::text,
+			state,
+			sync_state,
+			EXTRACT(EPOCH FROM write_lag)::float8,
+			EXTRACT(EPOCH FROM flush_lag)::float8,
+			EXTRACT(EPOCH FROM replay_lag)::float8,
+			pg_wal_lsn_diff(`+currentLSN+`, replay_lsn)::bigint
+		FROM pg_stat_replication
+		ORDER BY application_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_replication: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var replica model.Replica
+		if err := rows.Scan(
+			&replica.PID,
+			&replica.ApplicationName,
+			&replica.ClientAddr,
+			&replica.State,
+			&replica.SyncState,
+			&replica.WriteLag,
+			&replica.FlushLag,
+			&replica.ReplayLag,
+			&replica.LagBytes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse pg_stat_replication: %v", err)
+		}
+		status.Replicas = append(status.Replicas, replica)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pg_stat_replication: %v", err)
+	}
+
+	slotRows, err := p.db.QueryContext(ctx, `SELECT slot_name,
+			slot_type,
+			database,
+			active,
+			pg_wal_lsn_diff(`+currentLSN+`, restart_lsn)::bigint
+		FROM pg_replication_slots
+		ORDER BY slot_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_replication_slots: %v", err)
+	}
+	defer slotRows.Close()
+
+	for slotRows.Next() {
+		var slot model.ReplicationSlot
+		if err := slotRows.Scan(
+			&slot.SlotName,
+			&slot.SlotType,
+			&slot.Database,
+			&slot.Active,
+			&slot.RetainedBytes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse pg_replication_slots: %v", err)
+		}
+		status.Slots = append(status.Slots, slot)
+	}
+
+	if err := slotRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pg_replication_slots: %v", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"p6s/internal/model"
+)
+
+// GetSchemaObjects retrieves tables, views, materialized views, sequences and functions of all user schemas
+func (p *PostgresDB) GetSchemaObjects() ([]model.SchemaObject, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
+	if err != nil {
+		return nil, err
+	}
+
+	// pg_get_functiondef rejects aggregates, flagged by prokind since PostgreSQL 11
+	notAggregate := "NOT pr.proisagg"
+	if versionNum >= 110000 {
+		notAggregate = "pr.prokind <> 'a'"
+	}
+
+	query := fmt.Sprintf(`SELECT c.oid::bigint, n.nspname, c.relname,
+			CASE c.relkind
+				WHEN 'v' THEN '%[1]s'
+				WHEN 'm' THEN '%[2]s'
+				WHEN 'S' THEN '%[3]s'
+				ELSE '%[4]s'
+			END,
+			''
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p', 'f', 'v', 'm', 'S')
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND n.nspname NOT LIKE 'pg\_toast%%'
+			AND n.nspname NOT LIKE 'pg\_temp\_%%'
+		UNION ALL
+		SELECT pr.oid::bigint, n.nspname, pr.proname, '%[5]s', pg_get_function_identity_arguments(pr.oid)
+		FROM pg_proc pr
+		JOIN pg_namespace n ON n.oid = pr.pronamespace
+		WHERE %[6]s
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY 2, 4, 3, 5`,
+		model.ObjectView, model.ObjectMaterializedView, model.ObjectSequence, model.ObjectTable, model.ObjectFunction, notAggregate)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema objects: %v", err)
+	}
+	defer rows.Close()
+
+	var objects []model.SchemaObject
+	for rows.Next() {
+		var object model.SchemaObject
+		if err := rows.Scan(&object.OID, &object.Schema, &object.Name, &object.Kind, &object.Arguments); err != nil {
+			return nil, fmt.Errorf("failed to parse schema objects: %v", err)
+		}
+		objects = append(objects, object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate schema objects: %v", err)
+	}
+
+	return objects, nil
+}
+
+// GetFunctionDetail retrieves the signature and source of a function by oid
+func (p *PostgresDB) GetFunctionDetail(oid int64) (*model.FunctionDetail, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var detail model.FunctionDetail
+	if err := p.db.QueryRowContext(ctx, `SELECT n.nspname,
+			pr.proname,
+			pg_get_function_identity_arguments(pr.oid),
+			pg_get_function_result(pr.oid),
+			l.lanname,
+			pg_get_userbyid(pr.proowner),
+			pg_get_functiondef(pr.oid)
+		FROM pg_proc pr
+		JOIN pg_namespace n ON n.oid = pr.pronamespace
+		JOIN pg_language l ON l.oid = pr.prolang
+		WHERE pr.oid = $1`, oid).Scan(
+		&detail.Schema,
+		&detail.Name,
+		&detail.Arguments,
+		&detail.Result,
+		&detail.Language,
+		&detail.Owner,
+		&detail.Definition,
+	); err != nil {
+		return nil, fmt.Errorf("failed to query function: %v", err)
+	}
+
+	return &detail, nil
+}
+
+// GetSequenceDetail retrieves the settings and current value of a sequence
+func (p *PostgresDB) GetSequenceDetail(schema, name string) (*model.SequenceDetail, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	detail := model.SequenceDetail{Schema: schema, Name: name}
+
+	// pg_sequences exists since PostgreSQL 10, before that the settings are columns of the sequence itself
+	if versionNum >= 100000 {
+		err = p.db.QueryRowContext(ctx, `SELECT data_type::text, start_value, min_value, max_value, increment_by, cycle, cache_size, last_value
+			FROM pg_sequences
+			WHERE schemaname = $1 AND sequencename = $2`, schema, name).Scan(
+			&detail.DataType,
+			&detail.StartValue,
+			&detail.MinValue,
+			&detail.MaxValue,
+			&detail.Increment,
+			&detail.Cycle,
+			&detail.CacheSize,
+			&detail.LastValue,
+		)
+	} else {
+		detail.DataType = "bigint"
+		err = p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT start_value, min_value, max_value, increment_by, is_cycled, cache_value,
+				CASE WHEN is_called THEN last_value END
+			FROM %s.%s`, pq.QuoteIdentifier(schema), pq.QuoteIdentifier(name))).Scan(
+			&detail.StartValue,
+			&detail.MinValue,
+			&detail.MaxValue,
+			&detail.Increment,
+			&detail.Cycle,
+			&detail.CacheSize,
+			&detail.LastValue,
+		)
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sequence %s.%s not found", schema, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequence: %v", err)
+	}
+
+	return &detail, nil
+}
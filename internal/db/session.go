@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// session is a connection reserved for a script, so that its statements share SET, SET ROLE,
+// temporary tables and the like as they would in psql. A transaction the script begins runs on it too.
+type session struct {
+	conn *sql.Conn
+	pid  int
+}
+
+// BeginSession reserves a connection that the following custom queries and BEGIN run on until EndSession
+func (p *PostgresDB) BeginSession() error {
+	if p.db == nil {
+		return fmt.Errorf("database not connected")
+	}
+	if p.session != nil {
+		return fmt.Errorf("there is already a session in progress")
+	}
+	// A script run in the explicit transaction goes on with its connection after COMMIT
+	if p.tx != nil {
+		p.session = &session{conn: p.tx.conn, pid: p.tx.pid}
+		p.tx.session = true
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %v", err)
+	}
+
+	var pid int
+	if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to get backend PID: %v", err)
+	}
+
+	p.addBackend(pid)
+	p.session = &session{conn: conn, pid: pid}
+	return nil
+}
+
+// EndSession resets the connection of the session and returns it to the pool. A transaction the
+// script left open keeps the connection until COMMIT or ROLLBACK.
+func (p *PostgresDB) EndSession() {
+	s := p.session
+	p.session = nil
+	if s == nil {
+		return
+	}
+
+	if p.tx != nil && p.tx.conn == s.conn {
+		p.tx.session = false
+		return
+	}
+	p.removeBackend(s.pid)
+	releaseConn(s.conn)
+}
@@ -0,0 +1,324 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of the tokens scanSQL returns
+const (
+	tokenWord = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+// sqlToken is a token of a SQL text. Comments and whitespace are skipped, string literals,
+// dollar quoted strings and quoted identifiers are single tokens.
+type sqlToken struct {
+	kind  int
+	text  string
+	start int
+	end   int
+}
+
+// keyword returns the upper-cased text of a word token, empty for other tokens
+func (t sqlToken) keyword() string {
+	if t.kind != tokenWord {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// scanSQL splits a SQL text into tokens the way the PostgreSQL lexer does for the constructs
+// that matter to finding statement boundaries and keywords: -- and nested /* */ comments,
+// 'strings' with doubled quote escapes, E'strings' with backslash escapes, $tag$ dollar quoted strings$tag$
+// and "quoted identifiers". An unterminated literal or comment extends to the end of the text.
+func scanSQL(sql string) []sqlToken {
+	var tokens []sqlToken
+	i := 0
+	for i < len(sql) {
+		c := sql[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+			continue
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+			continue
+		case strings.HasPrefix(sql[i:], "/*"):
+			i = len(sql) - len(skipBlockComment(sql[i:]))
+			continue
+		case c == '\'':
+			i = scanQuoted(sql, i+1, '\'', false)
+			tokens = append(tokens, sqlToken{kind: tokenString, text: sql[start:i], start: start, end: i})
+		case (c == 'E' || c == 'e') && i+1 < len(sql) && sql[i+1] == '\'':
+			i = scanQuoted(sql, i+2, '\'', true)
+			tokens = append(tokens, sqlToken{kind: tokenString, text: sql[start:i], start: start, end: i})
+		case c == '"':
+			i = scanQuoted(sql, i+1, '"', false)
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdent, text: sql[start:i], start: start, end: i})
+		case c == '$':
+			if tag, ok := dollarQuoteTag(sql[i:]); ok {
+				end := strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					i = len(sql)
+				} else {
+					i += len(tag) + end + len(tag)
+				}
+				tokens = append(tokens, sqlToken{kind: tokenString, text: sql[start:i], start: start, end: i})
+				continue
+			}
+			// Positional parameter such as $1
+			i++
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: sql[start:i], start: start, end: i})
+		case isIdentStart(c):
+			for i < len(sql) && (isIdentStart(sql[i]) || isDigit(sql[i]) || sql[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: sql[start:i], start: start, end: i})
+		case isDigit(c):
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.' || sql[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: sql[start:i], start: start, end: i})
+		default:
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: sql[start:i], start: start, end: i})
+		}
+	}
+	return tokens
+}
+
+// scanQuoted returns the position after the literal whose body starts at i and ends with quote.
+// A doubled quote is part of the literal, as is a backslash-escaped character when backslash is set.
+func scanQuoted(sql string, i int, quote byte, backslash bool) int {
+	for i < len(sql) {
+		switch {
+		case backslash && sql[i] == '\\':
+			i += 2
+		case sql[i] == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(sql)
+}
+
+// dollarQuoteTag returns the opening $tag$ of a dollar quoted string s starts with
+func dollarQuoteTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1], true
+		case isIdentStart(s[i]), i > 1 && isDigit(s[i]):
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// isIdentStart reports whether c can start an identifier, non-ASCII letters included
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Statement is one statement of a SQL script
+type Statement struct {
+	// Text is the statement without its terminating semicolon
+	Text string
+	// Line is the 1-based line of the script the statement starts on
+	Line int
+	// Args are the values of the $n parameters of Text, see BindParameters
+	Args []interface{}
+	// Named is the statement as written with its :name placeholders, empty when none were bound
+	Named string
+	// Params are the values bound to the :name placeholders of Named
+	Params map[string]string
+}
+
+// Source returns the statement as written, with its :name placeholders
+func (s Statement) Source() string {
+	if s.Named != "" {
+		return s.Named
+	}
+	return s.Text
+}
+
+// SplitStatements splits a SQL script into its statements at the semicolons outside of string
+// literals, dollar quoted strings, quoted identifiers, comments and BEGIN ATOMIC function bodies.
+// Comments between statements are dropped, empty statements are skipped.
+func SplitStatements(script string) []Statement {
+	var statements []Statement
+	tokens := scanSQL(script)
+
+	first, last := -1, -1
+	atomicDepth := 0
+	flush := func() {
+		if first >= 0 {
+			start := tokens[first].start
+			statements = append(statements, Statement{
+				Text: script[start:tokens[last].end],
+				Line: strings.Count(script[:start], "\n") + 1,
+			})
+		}
+		first = -1
+		atomicDepth = 0
+	}
+
+	for i, token := range tokens {
+		if token.kind == tokenSymbol && token.text == ";" && atomicDepth == 0 {
+			flush()
+			continue
+		}
+		last = i
+		if first < 0 {
+			first = i
+		}
+
+		// The SQL-standard body of CREATE FUNCTION / PROCEDURE contains semicolons; CASE ... END
+		// nests inside it like in psql
+		switch token.keyword() {
+		case "BEGIN":
+			if atomicDepth > 0 || i+1 < len(tokens) && tokens[i+1].keyword() == "ATOMIC" && tokens[first].keyword() == "CREATE" {
+				atomicDepth++
+			}
+		case "CASE":
+			if atomicDepth > 0 {
+				atomicDepth++
+			}
+		case "END":
+			if atomicDepth > 0 {
+				atomicDepth--
+			}
+		}
+	}
+	flush()
+	return statements
+}
+
+// namedParameters returns the :name placeholders of a SQL text, skipping :: casts, array slices
+// such as a[lo:hi] and a[1:n] and anything inside literals and comments. A placeholder is a colon
+// directly followed by an identifier.
+func namedParameters(tokens []sqlToken) []int {
+	var colons []int
+	brackets := 0
+	for i := 0; i+1 < len(tokens); i++ {
+		token, next := tokens[i], tokens[i+1]
+		switch token.text {
+		case "[":
+			brackets++
+		case "]":
+			if brackets > 0 {
+				brackets--
+			}
+		}
+		if token.text != ":" || next.kind != tokenWord || next.start != token.end {
+			continue
+		}
+		// Not the second colon of a cast
+		if i > 0 && tokens[i-1].text == ":" && tokens[i-1].end == token.start {
+			continue
+		}
+		// Not the colon of a slice, which follows its lower bound: a[lo:hi] but ARRAY[:a, :b]
+		if brackets > 0 && i > 0 && isOperand(tokens[i-1]) {
+			continue
+		}
+		colons = append(colons, i)
+	}
+	return colons
+}
+
+// isOperand reports whether a token ends an expression, such as the lower bound of a slice
+func isOperand(token sqlToken) bool {
+	switch token.kind {
+	case tokenWord, tokenQuotedIdent, tokenString, tokenNumber:
+		return true
+	}
+	return token.text == ")" || token.text == "]" || len(token.text) > 1 && token.text[0] == '$'
+}
+
+// hasPositionalParameters reports whether a SQL text uses $n parameters
+func hasPositionalParameters(tokens []sqlToken) bool {
+	for _, token := range tokens {
+		if token.kind == tokenSymbol && len(token.text) > 1 && token.text[0] == '$' {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryParameters returns the names of the :name placeholders of a SQL text in the order they first appear
+func QueryParameters(sql string) []string {
+	tokens := scanSQL(sql)
+	seen := map[string]bool{}
+
+	var names []string
+	for _, i := range namedParameters(tokens) {
+		name := tokens[i+1].text
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// BindParameters replaces the :name placeholders of a statement with $n parameters whose values
+// are taken from values, so they are sent to the server separately and never become part of the
+// SQL text. A name used several times becomes the same parameter. Statements that already use
+// $n parameters cannot have placeholders, their numbers would collide.
+func BindParameters(statement Statement, values map[string]string) (Statement, error) {
+	tokens := scanSQL(statement.Text)
+	colons := namedParameters(tokens)
+	if len(colons) == 0 {
+		return statement, nil
+	}
+	if hasPositionalParameters(tokens) {
+		return Statement{}, fmt.Errorf("the statement on line %d mixes $n parameters with :name placeholders", statement.Line)
+	}
+	numbers := map[string]int{}
+	params := map[string]string{}
+
+	var sb strings.Builder
+	var args []interface{}
+	last := 0
+	for _, i := range colons {
+		name := tokens[i+1].text
+		number, ok := numbers[name]
+		if !ok {
+			args = append(args, values[name])
+			params[name] = values[name]
+			number = len(args)
+			numbers[name] = number
+		}
+
+		sb.WriteString(statement.Text[last:tokens[i].start])
+		sb.WriteString(fmt.Sprintf("$%d", number))
+		last = tokens[i+1].end
+	}
+	sb.WriteString(statement.Text[last:])
+
+	return Statement{Text: sb.String(), Line: statement.Line, Args: args, Named: statement.Text, Params: params}, nil
+}
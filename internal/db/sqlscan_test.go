@@ -0,0 +1,124 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []Statement
+	}{
+		{"single", "SELECT 1", []Statement{{Text: "SELECT 1", Line: 1}}},
+		{"several", "SELECT 1;\nSELECT 2;\n\nSELECT 3;",
+			[]Statement{{Text: "SELECT 1", Line: 1}, {Text: "SELECT 2", Line: 2}, {Text: "SELECT 3", Line: 4}}},
+		{"empty statements", ";; SELECT 1 ;;", []Statement{{Text: "SELECT 1", Line: 1}}},
+		{"string literal", "SELECT 'a;b'; SELECT 'it''s;'",
+			[]Statement{{Text: "SELECT 'a;b'", Line: 1}, {Text: "SELECT 'it''s;'", Line: 1}}},
+		{"escape string", `SELECT E'a\';b'; SELECT 2`,
+			[]Statement{{Text: `SELECT E'a\';b'`, Line: 1}, {Text: "SELECT 2", Line: 1}}},
+		{"backslash in standard string", `SELECT 'a\'; SELECT 2`,
+			[]Statement{{Text: `SELECT 'a\'`, Line: 1}, {Text: "SELECT 2", Line: 1}}},
+		{"dollar quoting", "DO $$ BEGIN PERFORM 1; END $$; SELECT 2",
+			[]Statement{{Text: "DO $$ BEGIN PERFORM 1; END $$", Line: 1}, {Text: "SELECT 2", Line: 1}}},
+		{"tagged dollar quoting", "SELECT $fn$ $$; $fn$; SELECT 2",
+			[]Statement{{Text: "SELECT $fn$ $$; $fn$", Line: 1}, {Text: "SELECT 2", Line: 1}}},
+		{"positional parameter", "SELECT $1; SELECT $2",
+			[]Statement{{Text: "SELECT $1", Line: 1}, {Text: "SELECT $2", Line: 1}}},
+		{"quoted identifier", `SELECT 1 AS "a;b"; SELECT 2`,
+			[]Statement{{Text: `SELECT 1 AS "a;b"`, Line: 1}, {Text: "SELECT 2", Line: 1}}},
+		{"line comment", "SELECT 1; -- two; three\nSELECT 2",
+			[]Statement{{Text: "SELECT 1", Line: 1}, {Text: "SELECT 2", Line: 2}}},
+		{"nested block comment", "SELECT 1 /* a /* b; */ c; */; SELECT 2",
+			[]Statement{{Text: "SELECT 1", Line: 1}, {Text: "SELECT 2", Line: 1}}},
+		{"unterminated comment", "SELECT 1; /* SELECT 2;", []Statement{{Text: "SELECT 1", Line: 1}}},
+		{"begin atomic", "CREATE FUNCTION f() RETURNS int LANGUAGE sql\nBEGIN ATOMIC\n  SELECT 1;\n  SELECT CASE WHEN true THEN 2 END;\nEND;\nSELECT f()",
+			[]Statement{
+				{Text: "CREATE FUNCTION f() RETURNS int LANGUAGE sql\nBEGIN ATOMIC\n  SELECT 1;\n  SELECT CASE WHEN true THEN 2 END;\nEND", Line: 1},
+				{Text: "SELECT f()", Line: 6},
+			}},
+		{"transaction begin", "BEGIN; UPDATE t SET a = 1; END;",
+			[]Statement{{Text: "BEGIN", Line: 1}, {Text: "UPDATE t SET a = 1", Line: 1}, {Text: "END", Line: 1}}},
+		{"blank", " \n -- only a comment\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.script)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements(%q) = %+v, want %+v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanSQLKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		sql   string
+		kinds []int
+		texts []string
+	}{
+		{"words and symbols", "SELECT a.b, 1", []int{tokenWord, tokenWord, tokenSymbol, tokenWord, tokenSymbol, tokenNumber},
+			[]string{"SELECT", "a", ".", "b", ",", "1"}},
+		{"escape string", `E'\\' x`, []int{tokenString, tokenWord}, []string{`E'\\'`, "x"}},
+		{"dollar quoted", "$a$ $b$ $a$ y", []int{tokenString, tokenWord}, []string{"$a$ $b$ $a$", "y"}},
+		{"quoted identifier", `"a""b" c`, []int{tokenQuotedIdent, tokenWord}, []string{`"a""b"`, "c"}},
+		{"nested comment", "/* /* */ x */ y", []int{tokenWord}, []string{"y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kinds []int
+			var texts []string
+			for _, token := range scanSQL(tt.sql) {
+				kinds = append(kinds, token.kind)
+				texts = append(texts, token.text)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) || !reflect.DeepEqual(texts, tt.texts) {
+				t.Errorf("scanSQL(%q) = %v %q, want %v %q", tt.sql, kinds, texts, tt.kinds, tt.texts)
+			}
+		})
+	}
+}
+
+func TestBindParameters(t *testing.T) {
+	values := map[string]string{"id": "7", "ids": "{1,2}", "a": "x", "b": "y", "i": "2", "n": "3"}
+	tests := []struct {
+		name     string
+		text     string
+		wantText string
+		wantArgs []interface{}
+	}{
+		{"none", "SELECT 1", "SELECT 1", nil},
+		{"single", "SELECT * FROM users WHERE id = :id", "SELECT * FROM users WHERE id = $1", []interface{}{"7"}},
+		{"repeated", "SELECT :a, :b, :a", "SELECT $1, $2, $1", []interface{}{"x", "y"}},
+		{"cast", "SELECT :id::int, now()::date", "SELECT $1::int, now()::date", []interface{}{"7"}},
+		{"literal and comment", "SELECT ':id', \":id\" -- :id\n/* :id */", "SELECT ':id', \":id\" -- :id\n/* :id */", nil},
+		{"dollar quoted", "SELECT $$ :id $$", "SELECT $$ :id $$", nil},
+		{"array constructor", "SELECT ARRAY[:a, :b]", "SELECT ARRAY[$1, $2]", []interface{}{"x", "y"}},
+		{"any array", "SELECT * FROM t WHERE id = ANY(ARRAY[:ids])", "SELECT * FROM t WHERE id = ANY(ARRAY[$1])", []interface{}{"{1,2}"}},
+		{"subscript", "SELECT tags[:i] FROM t", "SELECT tags[$1] FROM t", []interface{}{"2"}},
+		{"slice", "SELECT a[lo:hi], a[1:n], a[f(x):n] FROM t", "SELECT a[lo:hi], a[1:n], a[f(x):n] FROM t", nil},
+		{"slice bounds", "SELECT a[:i:n] FROM t", "SELECT a[$1:n] FROM t", []interface{}{"2"}},
+		{"cast in subscript", "SELECT a[:i::int] FROM t", "SELECT a[$1::int] FROM t", []interface{}{"2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BindParameters(Statement{Text: tt.text, Line: 1}, values)
+			if err != nil {
+				t.Fatalf("BindParameters(%q) failed: %v", tt.text, err)
+			}
+			if got.Text != tt.wantText || !reflect.DeepEqual(got.Args, tt.wantArgs) {
+				t.Errorf("BindParameters(%q) = %q %v, want %q %v", tt.text, got.Text, got.Args, tt.wantText, tt.wantArgs)
+			}
+		})
+	}
+
+	if _, err := BindParameters(Statement{Text: "SELECT $1, :id", Line: 3}, values); err == nil {
+		t.Errorf("BindParameters with $n and :name parameters did not fail")
+	}
+}
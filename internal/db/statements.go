@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// Sort columns accepted by GetTopQueries
+const (
+	SortByTotalTime  = "total_time"
+	SortByMeanTime   = "mean_time"
+	SortByCalls      = "calls"
+	SortByRows       = "rows"
+	SortBySharedRead = "shared_blks_read"
+	SortByTempBlocks = "temp_blks"
+)
+
+// HasPgStatStatements checks whether pg_stat_statements is installed in current database
+func (p *PostgresDB) HasPgStatStatements() (bool, error) {
+	if p.db == nil {
+		return false, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var installed bool
+	if err := p.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')").Scan(&installed); err != nil {
+		return false, fmt.Errorf("failed to check pg_stat_statements: %v", err)
+	}
+
+	return installed, nil
+}
+
+// GetTopQueries retrieves top statements from pg_stat_statements ordered by sortBy
+func (p *PostgresDB) GetTopQueries(sortBy string) ([]model.TopQuery, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
+	if err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL 13 split *_time into planning and execution columns
+	totalTime, meanTime := "total_time", "mean_time"
+	if versionNum >= 130000 {
+		totalTime, meanTime = "total_exec_time", "mean_exec_time"
+	}
+
+	orderBy := map[string]string{
+		SortByTotalTime:  "s." + totalTime,
+		SortByMeanTime:   "s." + meanTime,
+		SortByCalls:      "s.calls",
+		SortByRows:       "s.rows",
+		SortBySharedRead: "s.shared_blks_read",
+		SortByTempBlocks: "s.temp_blks_read + s.temp_blks_written",
+	}[sortBy]
+	if orderBy == "" {
+		return nil, fmt.Errorf("unknown sort column: %s", sortBy)
+	}
+
+	query := fmt.Sprintf(`SELECT r.rolname,
+			s.calls,
+			s.%s,
+			s.%s,
+			s.rows,
+			s.shared_blks_hit,
+			s.shared_blks_read,
+			s.temp_blks_read,
+			s.temp_blks_written,
+			s.query
+		FROM pg_stat_statements s
+		LEFT JOIN pg_roles r ON r.oid = s.userid
+		WHERE s.dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		ORDER BY %s DESC
+		LIMIT 100`, totalTime, meanTime, orderBy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_statements: %v", err)
+	}
+	defer rows.Close()
+
+	var queries []model.TopQuery
+	for rows.Next() {
+		var q model.TopQuery
+		if err := rows.Scan(
+			&q.User,
+			&q.Calls,
+			&q.TotalTime,
+			&q.MeanTime,
+			&q.Rows,
+			&q.SharedBlksHit,
+			&q.SharedBlksRead,
+			&q.TempBlksRead,
+			&q.TempBlksWritten,
+			&q.Query,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse pg_stat_statements: %v", err)
+		}
+		queries = append(queries, q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pg_stat_statements: %v", err)
+	}
+
+	return queries, nil
+}
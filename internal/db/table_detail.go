@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// GetTableDetail retrieves columns, indexes, constraints, triggers, sizes and activity counters of a table
+func (p *PostgresDB) GetTableDetail(schema, name string) (*model.TableDetail, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	versionNum, err := p.GetServerVersionNum()
+	if err != nil {
+		return nil, err
+	}
+
+	// Declarative partitioning and identity columns exist since PostgreSQL 10, generated columns since 12
+	partitionKey, partitionOf, partitionBound := "NULL::text", "NULL::text", "NULL::text"
+	identity, generated := "''", "''"
+	if versionNum >= 100000 {
+		partitionKey = "CASE WHEN c.relkind = 'p' THEN pg_get_partkeydef(c.oid) END"
+		partitionOf = "CASE WHEN c.relispartition THEN (SELECT inhparent::regclass::text FROM pg_inherits WHERE inhrelid = c.oid) END"
+		partitionBound = "CASE WHEN c.relispartition THEN pg_get_expr(c.relpartbound, c.oid) END"
+		identity = "a.attidentity::text"
+	}
+	if versionNum >= 120000 {
+		generated = "a.attgenerated::text"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	detail := &model.TableDetail{}
+	var oid int64
+
+	err = p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT c.oid,
+			n.nspname,
+			c.relname,
+			quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+			c.relkind::text,
+			pg_get_userbyid(c.relowner),
+			c.relpersistence::text,
+			pg_total_relation_size(c.oid),
+			pg_relation_size(c.oid),
+			pg_indexes_size(c.oid),
+			coalesce(pg_total_relation_size(nullif(c.reltoastrelid, 0)), 0),
+			greatest(c.reltuples, 0)::bigint,
+			coalesce(array_to_string(c.reloptions, ', '), ''),
+			obj_description(c.oid, 'pg_class'),
+			%s,
+			%s,
+			%s,
+			CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid) END,
+			s.relid IS NOT NULL,
+			coalesce(s.seq_scan, 0),
+			coalesce(s.seq_tup_read, 0),
+			coalesce(s.idx_scan, 0),
+			coalesce(s.idx_tup_fetch, 0),
+			coalesce(s.n_tup_ins, 0),
+			coalesce(s.n_tup_upd, 0),
+			coalesce(s.n_tup_del, 0),
+			coalesce(s.n_tup_hot_upd, 0),
+			coalesce(s.n_live_tup, 0),
+			coalesce(s.n_dead_tup, 0)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_stat_all_tables s ON s.relid = c.oid
+		WHERE n.nspname = $1 AND c.relname = $2`, partitionKey, partitionOf, partitionBound), schema, name).Scan(
+		&oid,
+		&detail.Schema,
+		&detail.Name,
+		&detail.QualifiedName,
+		&detail.Kind,
+		&detail.Owner,
+		&detail.Persistence,
+		&detail.TotalSize,
+		&detail.TableSize,
+		&detail.IndexSize,
+		&detail.ToastSize,
+		&detail.RowEstimate,
+		&detail.Options,
+		&detail.Comment,
+		&detail.PartitionKey,
+		&detail.PartitionOf,
+		&detail.PartitionBound,
+		&detail.ViewDefinition,
+		&detail.HasStats,
+		&detail.SeqScan,
+		&detail.SeqTupRead,
+		&detail.IdxScan,
+		&detail.IdxTupFetch,
+		&detail.TupInserted,
+		&detail.TupUpdated,
+		&detail.TupDeleted,
+		&detail.TupHotUpdated,
+		&detail.LiveTuples,
+		&detail.DeadTuples,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table %s.%s not found", schema, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %v", err)
+	}
+
+	columnRows, err := p.db.QueryContext(ctx, fmt.Sprintf(`SELECT a.attname,
+			quote_ident(a.attname),
+			format_type(a.atttypid, a.atttypmod),
+			a.attnotnull,
+			pg_get_expr(d.adbin, d.adrelid),
+			%s,
+			%s,
+			CASE WHEN a.attcollation <> t.typcollation THEN quote_ident(co.collname) END,
+			col_description(a.attrelid, a.attnum)
+		FROM pg_attribute a
+		JOIN pg_type t ON t.oid = a.atttypid
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		LEFT JOIN pg_collation co ON co.oid = a.attcollation
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, identity, generated), oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %v", err)
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var column model.TableColumn
+		if err := columnRows.Scan(
+			&column.Name,
+			&column.QuotedName,
+			&column.Type,
+			&column.NotNull,
+			&column.Default,
+			&column.Identity,
+			&column.Generated,
+			&column.Collation,
+			&column.Comment,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse columns: %v", err)
+		}
+		detail.Columns = append(detail.Columns, column)
+	}
+
+	if err := columnRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate columns: %v", err)
+	}
+
+	indexRows, err := p.db.QueryContext(ctx, `SELECT ci.relname,
+			pg_get_indexdef(i.indexrelid),
+			i.indisprimary,
+			i.indisunique,
+			i.indisvalid,
+			pg_relation_size(i.indexrelid),
+			coalesce(s.idx_scan, 0),
+			EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid AND con.conrelid = i.indrelid AND con.contype IN ('p', 'u', 'x'))
+		FROM pg_index i
+		JOIN pg_class ci ON ci.oid = i.indexrelid
+		LEFT JOIN pg_stat_all_indexes s ON s.indexrelid = i.indexrelid
+		WHERE i.indrelid = $1
+		ORDER BY i.indisprimary DESC, ci.relname`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %v", err)
+	}
+	defer indexRows.Close()
+
+	for indexRows.Next() {
+		var index model.TableIndex
+		if err := indexRows.Scan(
+			&index.Name,
+			&index.Definition,
+			&index.Primary,
+			&index.Unique,
+			&index.Valid,
+			&index.Size,
+			&index.Scans,
+			&index.ConstraintOf,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse indexes: %v", err)
+		}
+		detail.Indexes = append(detail.Indexes, index)
+	}
+
+	if err := indexRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate indexes: %v", err)
+	}
+
+	// NOT NULL constraints are catalogued since PostgreSQL 18 but already shown on the columns
+	constraintRows, err := p.db.QueryContext(ctx, `SELECT conname,
+			quote_ident(conname),
+			CASE contype
+				WHEN 'p' THEN 'PRIMARY KEY'
+				WHEN 'u' THEN 'UNIQUE'
+				WHEN 'f' THEN 'FOREIGN KEY'
+				WHEN 'c' THEN 'CHECK'
+				WHEN 'x' THEN 'EXCLUDE'
+				ELSE contype::text
+			END,
+			pg_get_constraintdef(oid)
+		FROM pg_constraint
+		WHERE conrelid = $1 AND contype <> 'n'
+		ORDER BY array_position(ARRAY['p', 'u', 'f', 'c', 'x'], contype::text), conname`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %v", err)
+	}
+	defer constraintRows.Close()
+
+	for constraintRows.Next() {
+		var constraint model.TableConstraint
+		if err := constraintRows.Scan(
+			&constraint.Name,
+			&constraint.QuotedName,
+			&constraint.Type,
+			&constraint.Definition,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse constraints: %v", err)
+		}
+		detail.Constraints = append(detail.Constraints, constraint)
+	}
+
+	if err := constraintRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate constraints: %v", err)
+	}
+
+	triggerRows, err := p.db.QueryContext(ctx, `SELECT tgname,
+			pg_get_triggerdef(oid),
+			tgenabled::text
+		FROM pg_trigger
+		WHERE tgrelid = $1 AND NOT tgisinternal
+		ORDER BY tgname`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %v", err)
+	}
+	defer triggerRows.Close()
+
+	for triggerRows.Next() {
+		var trigger model.TableTrigger
+		if err := triggerRows.Scan(&trigger.Name, &trigger.Definition, &trigger.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to parse triggers: %v", err)
+		}
+		detail.Triggers = append(detail.Triggers, trigger)
+	}
+
+	if err := triggerRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate triggers: %v", err)
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Transaction states reported by TransactionState
+const (
+	TransactionNone   = ""
+	TransactionOpen   = "open"
+	TransactionFailed = "failed"
+)
+
+// transaction is an explicit transaction opened with BEGIN. Custom queries run on its connection
+// until COMMIT or ROLLBACK releases it back to the pool, unless it is the connection of a script.
+type transaction struct {
+	conn *sql.Conn
+	pid  int
+	// session is set when the transaction runs on the connection of a script, which keeps it afterwards
+	session bool
+
+	mu     sync.Mutex
+	failed bool
+}
+
+// setFailed records that a statement failed and the transaction only accepts ROLLBACK
+func (t *transaction) setFailed() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = true
+}
+
+// isFailed reports whether a statement of the transaction failed
+func (t *transaction) isFailed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+// Begin opens an explicit transaction for the following custom queries. statement is the
+// BEGIN or START TRANSACTION statement as typed, with its options, or empty for a plain BEGIN.
+func (p *PostgresDB) Begin(statement string) error {
+	if p.db == nil {
+		return fmt.Errorf("database not connected")
+	}
+	if p.tx != nil {
+		return fmt.Errorf("there is already a transaction in progress")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if statement == "" {
+		statement = "BEGIN"
+	}
+
+	if s := p.session; s != nil {
+		if _, err := s.conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to begin transaction: %v", err)
+		}
+		p.tx = &transaction{conn: s.conn, pid: s.pid, session: true}
+		return nil
+	}
+
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %v", err)
+	}
+
+	var pid int
+	if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to get backend PID: %v", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, statement); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	p.addBackend(pid)
+	p.tx = &transaction{conn: conn, pid: pid}
+	return nil
+}
+
+// Commit commits the open transaction. statement is the COMMIT or END statement as typed, with its
+// options, or empty for a plain COMMIT. A failed transaction is rolled back by the server instead,
+// which is reported as an error.
+func (p *PostgresDB) Commit(statement string) error {
+	if p.tx == nil {
+		return fmt.Errorf("there is no transaction in progress")
+	}
+	failed := p.tx.isFailed()
+
+	if statement == "" {
+		statement = "COMMIT"
+	}
+	if err := p.endTransaction(statement); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	if failed {
+		return fmt.Errorf("transaction was aborted by an error and has been rolled back")
+	}
+	return nil
+}
+
+// Rollback rolls back the open transaction. statement is the ROLLBACK or ABORT statement as typed,
+// with its options, or empty for a plain ROLLBACK.
+func (p *PostgresDB) Rollback(statement string) error {
+	if p.tx == nil {
+		return fmt.Errorf("there is no transaction in progress")
+	}
+
+	if statement == "" {
+		statement = "ROLLBACK"
+	}
+	if err := p.endTransaction(statement); err != nil {
+		return fmt.Errorf("failed to roll back transaction: %v", err)
+	}
+	return nil
+}
+
+// endTransaction runs COMMIT or ROLLBACK and releases the connection of the transaction. The
+// transaction is over even when the statement fails: the connection is discarded then, which
+// makes the server roll it back. AND CHAIN starts a new transaction on the same connection instead.
+func (p *PostgresDB) endTransaction(statement string) error {
+	tx := p.tx
+	p.tx = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := tx.conn.ExecContext(ctx, statement)
+	if err == nil && chainsTransaction(statement) {
+		p.tx = &transaction{conn: tx.conn, pid: tx.pid, session: tx.session}
+		return nil
+	}
+	// The script goes on using its connection, EndSession resets it
+	if tx.session {
+		return err
+	}
+	p.removeBackend(tx.pid)
+	if err != nil {
+		discardConn(tx.conn)
+		tx.conn.Close()
+		return err
+	}
+	releaseConn(tx.conn)
+	return nil
+}
+
+// chainsTransaction reports whether a COMMIT or ROLLBACK statement has the AND CHAIN option
+func chainsTransaction(statement string) bool {
+	tokens := scanSQL(statement)
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].keyword() == "AND" && tokens[i+1].keyword() == "CHAIN" {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionState returns whether an explicit transaction is open or failed
+func (p *PostgresDB) TransactionState() string {
+	switch {
+	case p.tx == nil:
+		return TransactionNone
+	case p.tx.isFailed():
+		return TransactionFailed
+	}
+	return TransactionOpen
+}
+
+// InTransaction reports whether an explicit transaction is open
+func (p *PostgresDB) InTransaction() bool {
+	return p.tx != nil
+}
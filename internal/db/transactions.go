@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"p6s/internal/model"
+)
+
+// longTransactionFilter matches sessions idle in transaction longer than $1 seconds
+// or with a transaction open longer than $2 seconds. p6s's own backends, the array $3, are left
+// out: the result cursor and the explicit transaction are open on purpose.
+const longTransactionFilter = `a.pid <> pg_backend_pid()
+		AND a.pid <> ALL($3)
+		AND a.xact_start IS NOT NULL
+		AND (
+			(a.state LIKE 'idle in transaction%' AND now() - a.state_change > make_interval(secs => $1))
+			OR now() - a.xact_start > make_interval(secs => $2)
+		)`
+
+// GetLongTransactions retrieves sessions idle in transaction or running long transactions, oldest first
+func (p *PostgresDB) GetLongTransactions(idleThreshold, xactThreshold time.Duration) ([]model.Connection, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	query := `SELECT ` + fmt.Sprintf(connectionColumns, "a") + `
+		FROM pg_stat_activity a
+		WHERE ` + longTransactionFilter + `
+		ORDER BY a.xact_start`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query, idleThreshold.Seconds(), xactThreshold.Seconds(), p.ownBackends())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query long transactions: %v", err)
+	}
+	defer rows.Close()
+
+	var connections []model.Connection
+	for rows.Next() {
+		conn, err := scanConnection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse long transactions: %v", err)
+		}
+		connections = append(connections, conn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate long transactions: %v", err)
+	}
+
+	return connections, nil
+}
+
+// TerminateLongTransactions terminates the given PIDs that still match the long transaction
+// thresholds, so sessions that finished their transaction since the dry run are spared.
+// It returns the PIDs that were signalled.
+func (p *PostgresDB) TerminateLongTransactions(pids []int, idleThreshold, xactThreshold time.Duration) ([]int, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	targets := make(pq.Int64Array, len(pids))
+	for i, pid := range pids {
+		targets[i] = int64(pid)
+	}
+
+	// OFFSET 0 keeps the planner from evaluating pg_terminate_backend before the filter
+	query := `SELECT t.pid
+		FROM (
+			SELECT a.pid
+			FROM pg_stat_activity a
+			WHERE ` + longTransactionFilter + `
+			AND a.pid = ANY($4)
+			OFFSET 0
+		) t
+		WHERE pg_terminate_backend(t.pid)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query, idleThreshold.Seconds(), xactThreshold.Seconds(), p.ownBackends(), targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to terminate sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var terminated []int
+	for rows.Next() {
+		var pid int
+		if err := rows.Scan(&pid); err != nil {
+			return nil, fmt.Errorf("failed to parse terminated sessions: %v", err)
+		}
+		terminated = append(terminated, pid)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate terminated sessions: %v", err)
+	}
+
+	return terminated, nil
+}
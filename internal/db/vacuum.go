@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// GetVacuumStatus retrieves running vacuums and per-table dead tuples, vacuum history and autovacuum thresholds
+func (p *PostgresDB) GetVacuumStatus() (*model.VacuumStatus, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status := &model.VacuumStatus{}
+
+	progressRows, err := p.db.QueryContext(ctx, `SELECT v.pid,
+			v.relid::regclass::text,
+			v.phase,
+			v.heap_blks_total,
+			v.heap_blks_scanned,
+			v.heap_blks_vacuumed,
+			v.index_vacuum_count,
+			coalesce(a.query LIKE 'autovacuum:%', false),
+			EXTRACT(EPOCH FROM now() - a.xact_start)::float8
+		FROM pg_stat_progress_vacuum v
+		LEFT JOIN pg_stat_activity a ON a.pid = v.pid
+		WHERE v.datname = current_database()
+		ORDER BY a.xact_start`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_progress_vacuum: %v", err)
+	}
+	defer progressRows.Close()
+
+	for progressRows.Next() {
+		var progress model.VacuumProgress
+		if err := progressRows.Scan(
+			&progress.PID,
+			&progress.Table,
+			&progress.Phase,
+			&progress.HeapBlksTotal,
+			&progress.HeapBlksScanned,
+			&progress.HeapBlksVacuumed,
+			&progress.IndexVacuumCount,
+			&progress.Autovacuum,
+			&progress.Duration,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse pg_stat_progress_vacuum: %v", err)
+		}
+		status.Running = append(status.Running, progress)
+	}
+
+	if err := progressRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pg_stat_progress_vacuum: %v", err)
+	}
+
+	// Table reloptions override the global autovacuum settings, reltuples is -1 for never analyzed tables since PG14
+	tableRows, err := p.db.QueryContext(ctx, `SELECT s.schemaname,
+			s.relname,
+			s.n_live_tup,
+			s.n_dead_tup,
+			s.n_mod_since_analyze,
+			coalesce(o.vacuum_threshold, current_setting('autovacuum_vacuum_threshold')::float8)
+				+ coalesce(o.vacuum_scale_factor, current_setting('autovacuum_vacuum_scale_factor')::float8) * greatest(c.reltuples, 0),
+			coalesce(o.analyze_threshold, current_setting('autovacuum_analyze_threshold')::float8)
+				+ coalesce(o.analyze_scale_factor, current_setting('autovacuum_analyze_scale_factor')::float8) * greatest(c.reltuples, 0),
+			current_setting('autovacuum')::boolean AND coalesce(o.enabled, true),
+			s.last_vacuum,
+			s.last_autovacuum,
+			s.last_analyze,
+			s.last_autoanalyze
+		FROM pg_stat_user_tables s
+		JOIN pg_class c ON c.oid = s.relid
+		LEFT JOIN LATERAL (
+			SELECT max(option_value) FILTER (WHERE option_name = 'autovacuum_vacuum_threshold')::float8 AS vacuum_threshold,
+				max(option_value) FILTER (WHERE option_name = 'autovacuum_vacuum_scale_factor')::float8 AS vacuum_scale_factor,
+				max(option_value) FILTER (WHERE option_name = 'autovacuum_analyze_threshold')::float8 AS analyze_threshold,
+				max(option_value) FILTER (WHERE option_name = 'autovacuum_analyze_scale_factor')::float8 AS analyze_scale_factor,
+				max(option_value) FILTER (WHERE option_name = 'autovacuum_enabled')::boolean AS enabled
+			FROM pg_options_to_table(c.reloptions)
+		) o ON true
+		ORDER BY s.n_dead_tup DESC, s.schemaname, s.relname
+		LIMIT 100`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table vacuum statistics: %v", err)
+	}
+	defer tableRows.Close()
+
+	for tableRows.Next() {
+		var table model.TableVacuum
+		if err := tableRows.Scan(
+			&table.Schema,
+			&table.Table,
+			&table.LiveTuples,
+			&table.DeadTuples,
+			&table.ModsSinceAnalyze,
+			&table.VacuumThreshold,
+			&table.AnalyzeThreshold,
+			&table.AutovacuumEnabled,
+			&table.LastVacuum,
+			&table.LastAutovacuum,
+			&table.LastAnalyze,
+			&table.LastAutoanalyze,
+		); err != nil {
+			return nil, fmt.Errorf("failed to parse table vacuum statistics: %v", err)
+		}
+		status.Tables = append(status.Tables, table)
+	}
+
+	if err := tableRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate table vacuum statistics: %v", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"p6s/internal/model"
+)
+
+// GetDatabaseAges retrieves the freeze limits and transaction ID and multixact ages of all databases
+func (p *PostgresDB) GetDatabaseAges() (*model.WraparoundStatus, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	status := &model.WraparoundStatus{}
+
+	if err := p.db.QueryRowContext(ctx, `SELECT current_setting('autovacuum_freeze_max_age')::bigint,
+			current_setting('autovacuum_multixact_freeze_max_age')::bigint`).Scan(
+		&status.FreezeMaxAge,
+		&status.MultixactFreezeMaxAge,
+	); err != nil {
+		return nil, fmt.Errorf("failed to query freeze settings: %v", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, `SELECT datname,
+			age(datfrozenxid),
+			mxid_age(datminmxid)
+		FROM pg_database
+		ORDER BY age(datfrozenxid) DESC, datname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database ages: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var database model.DatabaseAge
+		if err := rows.Scan(&database.Database, &database.XidAge, &database.MxidAge); err != nil {
+			return nil, fmt.Errorf("failed to parse database ages: %v", err)
+		}
+		status.Databases = append(status.Databases, database)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate database ages: %v", err)
+	}
+
+	return status, nil
+}
+
+// GetWraparoundStatus retrieves database ages and the tables of the current database with the oldest relfrozenxid
+func (p *PostgresDB) GetWraparoundStatus() (*model.WraparoundStatus, error) {
+	status, err := p.GetDatabaseAges()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Partitioned tables and views have no storage and therefore no relfrozenxid
+	rows, err := p.db.QueryContext(ctx, `SELECT n.nspname,
+			c.relname,
+			age(c.relfrozenxid),
+			mxid_age(c.relminmxid),
+			pg_total_relation_size(c.oid)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'm', 't')
+		ORDER BY age(c.relfrozenxid) DESC, n.nspname, c.relname
+		LIMIT 30`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table ages: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table model.TableAge
+		if err := rows.Scan(&table.Schema, &table.Table, &table.XidAge, &table.MxidAge, &table.Size); err != nil {
+			return nil, fmt.Errorf("failed to parse table ages: %v", err)
+		}
+		status.Tables = append(status.Tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate table ages: %v", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,15 @@
+package model
+
+// BloatEstimate represents the estimated bloat of a table or btree index
+type BloatEstimate struct {
+	Kind         string
+	Schema       string
+	Table        string
+	Index        string
+	RealSize     int64
+	BloatSize    int64
+	BloatPercent float64
+	Fillfactor   int
+	// Unreliable is set when statistics are missing or columns of type name skew the estimate
+	Unreliable bool
+}
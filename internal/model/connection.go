@@ -15,14 +15,26 @@ type Connection struct {
 	BackendStart   time.Time
 	State          sql.NullString
 	Query          sql.NullString
+	XactStart      sql.NullTime
+	QueryStart     sql.NullTime
+	StateChange    sql.NullTime
+	WaitEventType  sql.NullString
+	WaitEvent      sql.NullString
+	BackendType    sql.NullString
+	BackendXid     sql.NullString
+	BackendXmin    sql.NullString
 }
 
 // TableStat represents table statistics information
 type TableStat struct {
 	Schema     string
 	Name       string
+	Kind       string
 	TotalSize  string
 	TableSize  string
 	IndexSize  string
+	TotalBytes int64
+	TableBytes int64
+	IndexBytes int64
 	RowCount   int64
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// DatabaseStat represents pg_stat_database counters of the current database
+type DatabaseStat struct {
+	Database       string
+	Commits        int64
+	Rollbacks      int64
+	BlksRead       int64
+	BlksHit        int64
+	Deadlocks      int64
+	TempFiles      int64
+	TempBytes      int64
+	Conflicts      int64
+	Connections    int
+	MaxConnections int
+	CollectedAt    time.Time
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// HistoryEntry is a statement run as custom SQL, as recorded in the query history
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	// Profile identifies the connection as user@host:port
+	Profile  string `json:"profile"`
+	Database string `json:"database"`
+	SQL      string `json:"sql"`
+	// Params are the values bound to the :name placeholders of a saved query
+	Params     map[string]string `json:"params,omitempty"`
+	DurationMs int64             `json:"duration_ms"`
+	// Rows counts the rows of the first page, or the affected rows when Affected is set
+	Rows     int64 `json:"rows"`
+	MoreRows bool  `json:"more_rows,omitempty"`
+	Affected bool  `json:"affected,omitempty"`
+	// Error is the error message of a failed or cancelled statement, empty on success
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+// IndexReport represents index usage and schema issues of the current database
+type IndexReport struct {
+	Indexes              []IndexUsage
+	UnindexedForeignKeys []UnindexedForeignKey
+}
+
+// IndexUsage represents a row of pg_stat_user_indexes joined with pg_index
+type IndexUsage struct {
+	Schema      string
+	Table       string
+	Index       string
+	Scans       int64
+	Size        int64
+	Unique      bool
+	Primary     bool
+	Valid       bool
+	Method      string
+	Columns     string
+	Expressions string
+	Predicate   string
+	Definition  string
+	Issues      []string
+}
+
+// UnindexedForeignKey represents a foreign key whose columns do not lead any index of the referencing table
+type UnindexedForeignKey struct {
+	Schema     string
+	Table      string
+	Constraint string
+	Columns    string
+	Definition string
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LockNode represents a session in the blocker -> waiter lock tree
+type LockNode struct {
+	PID          int
+	BlockedBy    []int
+	RootPID      int
+	Depth        int
+	User         sql.NullString
+	Database     sql.NullString
+	State        sql.NullString
+	LockType     sql.NullString
+	LockMode     sql.NullString
+	Relation     sql.NullString
+	WaitDuration time.Duration
+	Query        sql.NullString
+}
+
+// Lock represents a row of pg_locks joined with its relation and session
+type Lock struct {
+	PID      sql.NullInt64
+	LockType string
+	Mode     sql.NullString
+	Granted  bool
+	Relation sql.NullString
+	User     sql.NullString
+	QueryAge sql.NullFloat64
+	State    sql.NullString
+	Query    sql.NullString
+}
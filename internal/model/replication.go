@@ -0,0 +1,42 @@
+package model
+
+import "database/sql"
+
+// ReplicationStatus represents replication state of the connected server
+type ReplicationStatus struct {
+	Recovery RecoveryStatus
+	Replicas []Replica
+	Slots    []ReplicationSlot
+}
+
+// RecoveryStatus represents standby receive/replay progress, only filled when in recovery
+type RecoveryStatus struct {
+	InRecovery     bool
+	ReceiverStatus sql.NullString
+	ReceiveLSN     sql.NullString
+	ReplayLSN      sql.NullString
+	LagBytes       sql.NullInt64
+	ReplayDelay    sql.NullFloat64
+}
+
+// Replica represents a row of pg_stat_replication
+type Replica struct {
+	PID             int
+	ApplicationName sql.NullString
+	ClientAddr      sql.NullString
+	State           sql.NullString
+	SyncState       sql.NullString
+	WriteLag        sql.NullFloat64
+	FlushLag        sql.NullFloat64
+	ReplayLag       sql.NullFloat64
+	LagBytes        sql.NullInt64
+}
+
+// ReplicationSlot represents a row of pg_replication_slots
+type ReplicationSlot struct {
+	SlotName      string
+	SlotType      string
+	Database      sql.NullString
+	Active        bool
+	RetainedBytes sql.NullInt64
+}
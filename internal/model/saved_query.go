@@ -0,0 +1,9 @@
+package model
+
+// SavedQuery is a named SQL query stored in the query library
+type SavedQuery struct {
+	Name string
+	// Description is taken from the comment lines at the top of the SQL
+	Description string
+	SQL         string
+}
@@ -0,0 +1,47 @@
+package model
+
+import "database/sql"
+
+// Kinds of schema objects listed by the schema browser
+const (
+	ObjectTable            = "table"
+	ObjectView             = "view"
+	ObjectMaterializedView = "materialized view"
+	ObjectSequence         = "sequence"
+	ObjectFunction         = "function"
+)
+
+// SchemaObject represents a table, view, materialized view, sequence or function of a schema
+type SchemaObject struct {
+	OID    int64
+	Schema string
+	Name   string
+	Kind   string
+	// Arguments holds the identity arguments of functions
+	Arguments string
+}
+
+// FunctionDetail represents a function or procedure with its source
+type FunctionDetail struct {
+	Schema     string
+	Name       string
+	Arguments  string
+	Result     sql.NullString
+	Language   string
+	Owner      string
+	Definition string
+}
+
+// SequenceDetail represents the settings and current value of a sequence
+type SequenceDetail struct {
+	Schema     string
+	Name       string
+	DataType   string
+	StartValue int64
+	MinValue   int64
+	MaxValue   int64
+	Increment  int64
+	Cycle      bool
+	CacheSize  int64
+	LastValue  sql.NullInt64
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Statuses of the statements of a SQL script
+const (
+	StatementPending   = "pending"
+	StatementRunning   = "running"
+	StatementOK        = "ok"
+	StatementFailed    = "error"
+	StatementCancelled = "cancelled"
+	StatementSkipped   = "skipped"
+)
+
+// StatementResult is the outcome of one statement of a SQL script
+type StatementResult struct {
+	// Line is the line of the script the statement starts on
+	Line    int
+	SQL     string
+	Status  string
+	Message string
+	// Rows counts the fetched rows, or the affected rows of INSERT, UPDATE and DELETE when Affected is set
+	Rows     int64
+	Affected bool
+	// MoreRows is set when the statement returned more rows than were fetched
+	MoreRows bool
+	Duration time.Duration
+	Headers  []string
+	Results  [][]interface{}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "database/sql"
+
+// TopQuery represents a normalized statement from pg_stat_statements
+type TopQuery struct {
+	User            sql.NullString
+	Calls           int64
+	TotalTime       float64
+	MeanTime        float64
+	Rows            int64
+	SharedBlksHit   int64
+	SharedBlksRead  int64
+	TempBlksRead    int64
+	TempBlksWritten int64
+	Query           sql.NullString
+}
@@ -0,0 +1,79 @@
+package model
+
+import "database/sql"
+
+// TableDetail represents the structure, sizes and activity of a single table
+type TableDetail struct {
+	Schema         string
+	Name           string
+	QualifiedName  string
+	Kind           string
+	Owner          string
+	Persistence    string
+	TotalSize      int64
+	TableSize      int64
+	IndexSize      int64
+	ToastSize      int64
+	RowEstimate    int64
+	Options        string
+	Comment        sql.NullString
+	PartitionKey   sql.NullString
+	PartitionOf    sql.NullString
+	PartitionBound sql.NullString
+	ViewDefinition sql.NullString
+	HasStats       bool
+	SeqScan        int64
+	SeqTupRead     int64
+	IdxScan        int64
+	IdxTupFetch    int64
+	TupInserted    int64
+	TupUpdated     int64
+	TupDeleted     int64
+	TupHotUpdated  int64
+	LiveTuples     int64
+	DeadTuples     int64
+	Columns        []TableColumn
+	Indexes        []TableIndex
+	Constraints    []TableConstraint
+	Triggers       []TableTrigger
+}
+
+// TableColumn represents a column of a table
+type TableColumn struct {
+	Name       string
+	QuotedName string
+	Type       string
+	NotNull    bool
+	Default    sql.NullString
+	Identity   string
+	Generated  string
+	Collation  sql.NullString
+	Comment    sql.NullString
+}
+
+// TableIndex represents an index of a table
+type TableIndex struct {
+	Name         string
+	Definition   string
+	Primary      bool
+	Unique       bool
+	Valid        bool
+	Size         int64
+	Scans        int64
+	ConstraintOf bool
+}
+
+// TableConstraint represents a constraint of a table
+type TableConstraint struct {
+	Name       string
+	QuotedName string
+	Type       string
+	Definition string
+}
+
+// TableTrigger represents a user trigger of a table
+type TableTrigger struct {
+	Name       string
+	Definition string
+	Enabled    string
+}
@@ -0,0 +1,38 @@
+package model
+
+import "database/sql"
+
+// VacuumStatus represents vacuum state of the tables in the current database
+type VacuumStatus struct {
+	Running []VacuumProgress
+	Tables  []TableVacuum
+}
+
+// VacuumProgress represents a row of pg_stat_progress_vacuum
+type VacuumProgress struct {
+	PID              int
+	Table            string
+	Phase            string
+	HeapBlksTotal    int64
+	HeapBlksScanned  int64
+	HeapBlksVacuumed int64
+	IndexVacuumCount int64
+	Autovacuum       bool
+	Duration         sql.NullFloat64
+}
+
+// TableVacuum represents dead tuples, vacuum history and autovacuum thresholds of a table
+type TableVacuum struct {
+	Schema            string
+	Table             string
+	LiveTuples        int64
+	DeadTuples        int64
+	ModsSinceAnalyze  int64
+	VacuumThreshold   float64
+	AnalyzeThreshold  float64
+	AutovacuumEnabled bool
+	LastVacuum        sql.NullTime
+	LastAutovacuum    sql.NullTime
+	LastAnalyze       sql.NullTime
+	LastAutoanalyze   sql.NullTime
+}
@@ -0,0 +1,16 @@
+package model
+
+// Value is a query result value together with the name of its PostgreSQL type as reported by
+// the driver, e.g. INT8, NUMERIC, TIMESTAMPTZ, JSONB or _TEXT for arrays.
+//
+// Data holds the driver value: nil for NULL, int64, float64, bool, time.Time, or []byte with
+// the text representation (raw bytes for bytea).
+type Value struct {
+	Type string
+	Data interface{}
+}
+
+// IsNull reports whether the value is SQL NULL
+func (v Value) IsNull() bool {
+	return v.Data == nil
+}
@@ -0,0 +1,25 @@
+package model
+
+// WraparoundStatus represents transaction ID and multixact ages against the autovacuum freeze limits
+type WraparoundStatus struct {
+	FreezeMaxAge          int64
+	MultixactFreezeMaxAge int64
+	Databases             []DatabaseAge
+	Tables                []TableAge
+}
+
+// DatabaseAge represents age(datfrozenxid) and mxid_age(datminmxid) of a database
+type DatabaseAge struct {
+	Database string
+	XidAge   int64
+	MxidAge  int64
+}
+
+// TableAge represents age(relfrozenxid) and mxid_age(relminmxid) of a table
+type TableAge struct {
+	Schema  string
+	Table   string
+	XidAge  int64
+	MxidAge int64
+	Size    int64
+}
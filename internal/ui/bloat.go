@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// DisplayBloatEstimates displays estimated table and btree index bloat in table
+func (c *Components) DisplayBloatEstimates(estimates []model.BloatEstimate) {
+
+	var rows [][]*tview.TableCell
+	for _, estimate := range estimates {
+
+		note := ""
+		if estimate.Unreliable {
+			note = "estimate unreliable (missing statistics or name columns)"
+		}
+
+		color := tcell.ColorWhite
+		switch {
+		case estimate.BloatPercent >= 50:
+			color = tcell.ColorRed
+		case estimate.BloatPercent >= 30:
+			color = tcell.ColorYellow
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(estimate.Kind),
+			tview.NewTableCell(estimate.Schema + "." + estimate.Table),
+			tview.NewTableCell(estimate.Index),
+			tview.NewTableCell(formatBytes(estimate.RealSize)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatBytes(estimate.BloatSize)).SetAlign(tview.AlignRight).SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%.1f%%", estimate.BloatPercent)).SetAlign(tview.AlignRight).SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%d", estimate.Fillfactor)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(note).SetTextColor(tcell.ColorGray),
+		})
+	}
+
+	c.displayRows(rows, "No bloat estimates, tables may not be analyzed yet")
+}
@@ -16,11 +16,19 @@ type Components struct {
 	FlexBox      *tview.Flex
 	MenuList     *tview.List
 	MenuList2    *tview.List
+	MenuList3    *tview.List
 	SwitchDBList *tview.List
+	KeyHelpList  *tview.List
 	ConnTable    *tview.Table
 	ConnInfo     *tview.TextView
 	CmdInput     *tview.InputField
+	Banner       *tview.TextView
 	TableHeaders []string
+	AutoRefresh  time.Duration
+	// TableStatus is shown in the result table title, e.g. the page of a paged view
+	TableStatus string
+	// HeaderClicked is called with the column index when a header cell of the result table is clicked
+	HeaderClicked func(column int)
 }
 
 // NewComponents creates and initializes UI components
@@ -39,6 +47,8 @@ func NewComponents() *Components {
 	components.MenuList.AddItem("Active Connections", "", '2', nil)
 	components.MenuList.AddItem("Block Connections", "", '3', nil)
 	components.MenuList.AddItem("Show Table Statics", "", '4', nil)
+	components.MenuList.AddItem("Show Locks", "", '7', nil)
+	components.MenuList.AddItem("Lock Tree", "", '6', nil)
 	components.MenuList.SetMainTextColor(tcell.ColorWhite)
 	components.MenuList.SetSelectedTextColor(tcell.ColorWhite)
 	components.MenuList.SetSelectedBackgroundColor(tcell.ColorBlack)
@@ -47,11 +57,26 @@ func NewComponents() *Components {
 	components.MenuList2 = tview.NewList().ShowSecondaryText(false)
 	components.MenuList2.SetBorder(false).SetTitle("Custom").SetTitleAlign(tview.AlignLeft)
 	components.MenuList2.AddItem("Custom Query", "", '5', nil)
+	components.MenuList2.AddItem("Schema Browser", "", 'D', nil)
 	components.MenuList2.SetMainTextColor(tcell.ColorWhite)
 	components.MenuList2.SetSelectedTextColor(tcell.ColorWhite)
 	components.MenuList2.SetSelectedBackgroundColor(tcell.ColorBlack)
 	
 
+	components.MenuList3 = tview.NewList().ShowSecondaryText(false)
+	components.MenuList3.SetBorder(false).SetTitle("Monitor").SetTitleAlign(tview.AlignLeft)
+	components.MenuList3.AddItem("Top Queries", "", '8', nil)
+	components.MenuList3.AddItem("Replication", "", '9', nil)
+	components.MenuList3.AddItem("Long Transactions", "", '0', nil)
+	components.MenuList3.AddItem("Vacuum", "", 'V', nil)
+	components.MenuList3.AddItem("Wraparound", "", 'W', nil)
+	components.MenuList3.AddItem("Index Report", "", 'I', nil)
+	components.MenuList3.AddItem("Bloat", "", 'B', nil)
+	components.MenuList3.SetMainTextColor(tcell.ColorWhite)
+	components.MenuList3.SetSelectedTextColor(tcell.ColorWhite)
+	components.MenuList3.SetSelectedBackgroundColor(tcell.ColorBlack)
+	
+
 	components.MenuList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		return nil
 	})
@@ -70,12 +95,26 @@ func NewComponents() *Components {
 	})
 	
 
+	components.MenuList3.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		return nil
+	})
+
+	components.MenuList3.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		return action, nil
+	})
+	
+
 	components.SwitchDBList = tview.NewList().ShowSecondaryText(false)
 	components.SwitchDBList.SetBorder(true).SetTitle("Help").SetTitleAlign(tview.AlignLeft)
 	components.SwitchDBList.AddItem("[::d] :            Enter Command Line[-]", "",0, nil)
 	components.SwitchDBList.AddItem("[::d] [\\c]         Switch Database[-]", "",0, nil)
 	components.SwitchDBList.AddItem("[::d] [\\config]    Configure Connection[-]", "",0, nil)
 	components.SwitchDBList.AddItem("[::d] [\\configk8s] Configure K8s Connection[-]", "",0, nil)
+	components.SwitchDBList.AddItem("[::d] [\\locks]     Locks of PID[-]", "",0, nil)
+	components.SwitchDBList.AddItem("[::d] [refresh N]  Auto Refresh Interval[-]", "",0, nil)
+	components.SwitchDBList.AddItem("[::d] [\\longtx I X] Long Tx Thresholds (s)[-]", "",0, nil)
+	components.SwitchDBList.AddItem("[::d] [\\tables P]  Filter Table Statics[-]", "",0, nil)
+	components.SwitchDBList.AddItem("[::d] [\\d \\dt \\x..] psql Meta Commands[-]", "",0, nil)
 	components.SwitchDBList.SetMainTextColor(tcell.ColorWhite)
 	components.SwitchDBList.SetSelectedTextColor(tcell.ColorDarkGrey)
 	components.SwitchDBList.SetSelectedBackgroundColor(tcell.ColorBlack)
@@ -88,6 +127,29 @@ func NewComponents() *Components {
 	components.SwitchDBList.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
 		return action, nil
 	})
+	
+
+	components.KeyHelpList = tview.NewList().ShowSecondaryText(false)
+	components.KeyHelpList.SetBorder(true).SetTitle("Keys").SetTitleAlign(tview.AlignLeft)
+	components.KeyHelpList.AddItem("[::d] Enter  Open Row Detail[-]", "",0, nil)
+	components.KeyHelpList.AddItem("[::d] C / K  Cancel / Terminate Backend[-]", "",0, nil)
+	components.KeyHelpList.AddItem("[::d] L      Locks of Selected PID[-]", "",0, nil)
+	components.KeyHelpList.AddItem("[::d] S      Switch Sort Column[-]", "",0, nil)
+	components.KeyHelpList.AddItem("[::d] N / P  Next / Previous Page[-]", "",0, nil)
+	components.KeyHelpList.AddItem("[::d] R      Toggle Auto Refresh[-]", "",0, nil)
+	components.KeyHelpList.AddItem("[::d] X      Terminate All Matching[-]", "",0, nil)
+	components.KeyHelpList.SetMainTextColor(tcell.ColorWhite)
+	components.KeyHelpList.SetSelectedTextColor(tcell.ColorDarkGrey)
+	components.KeyHelpList.SetSelectedBackgroundColor(tcell.ColorBlack)
+	
+
+	components.KeyHelpList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		return nil
+	})
+
+	components.KeyHelpList.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		return action, nil
+	})
 
 
 	headers := []string{"PID", "User", "Database", "Client Address", "Application Name", "Start Time", "Status", "Query"}
@@ -122,14 +184,35 @@ func NewComponents() *Components {
 	return components
 }
 
+// bannerText is the title shown above the menus, followed by the connection mode line
+const bannerText = "[yellow::b]╔══════════════════════════════════════════════════════════════════════════════╗[-:-:-]\n" +
+	"[yellow::b][white::b]                           🐘 p6s - Postgres TUI 💻                           [yellow::b][-:-:-]\n" +
+	"[yellow::b]╚══════════════════════════════════════════════════════════════════════════════╝[-:-:-]"
+
+// SetConnectionMode shows below the banner whether the connection is read-only or read-write
+// and whether an explicit transaction is open
+func (c *Components) SetConnectionMode(readWrite, inTransaction, transactionFailed bool) {
+	mode := "[gray::d]read-only[-:-:-]"
+	if readWrite {
+		mode = "[white:red:b] READ-WRITE [-:-:-]"
+	}
+
+	switch {
+	case transactionFailed:
+		mode += "  [white:red:b] TRANSACTION FAILED - ROLLBACK [-:-:-]"
+	case inTransaction:
+		mode += "  [black:yellow:b] TRANSACTION OPEN [-:-:-]"
+	}
+
+	c.Banner.SetText(bannerText + "\n" + mode)
+}
+
 // createLayout creates application layout
 func createLayout(c *Components) *tview.Flex {
 
 	p6sHeader := tview.NewTextView()
-	bannerText := "[yellow::b]╔══════════════════════════════════════════════════════════════════════════════╗[-:-:-]\n" +
-				 "[yellow::b][white::b]                           🐘 p6s - Postgres TUI 💻                           [yellow::b][-:-:-]\n" +
-				 "[yellow::b]╚══════════════════════════════════════════════════════════════════════════════╝[-:-:-]"
-	p6sHeader.SetText(bannerText)
+	c.Banner = p6sHeader
+	c.SetConnectionMode(false, false, false)
 	p6sHeader.SetDynamicColors(true)
 	p6sHeader.SetTextAlign(tview.AlignCenter)
 	p6sHeader.SetBorder(false)
@@ -139,6 +222,7 @@ func createLayout(c *Components) *tview.Flex {
 	optionsFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
 	optionsFlex.AddItem(c.MenuList, 0, 1, false)
 	optionsFlex.AddItem(c.MenuList2, 0, 1, false)
+	optionsFlex.AddItem(c.MenuList3, 0, 1, false)
 	
 
 	optionsAreaFlex := tview.NewFlex().SetDirection(tview.FlexRow)
@@ -146,7 +230,8 @@ func createLayout(c *Components) *tview.Flex {
 	
 
 	topMenuFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
-	topMenuFlex.AddItem(optionsAreaFlex, 0, 3, false)
+	topMenuFlex.AddItem(optionsAreaFlex, 0, 2, false)
+	topMenuFlex.AddItem(c.KeyHelpList, 0, 1, false)
 	topMenuFlex.AddItem(c.SwitchDBList, 0, 1, false)
 
 
@@ -157,7 +242,7 @@ func createLayout(c *Components) *tview.Flex {
 
 	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow)
 	mainFlex.AddItem(p6sHeader, 4, 0, false)
-	mainFlex.AddItem(topMenuFlex, 6, 0, false)
+	mainFlex.AddItem(topMenuFlex, 11, 0, false)
 	mainFlex.AddItem(bottomContentFlex, 0, 1, true)
 
 
@@ -175,8 +260,10 @@ func (c *Components) UpdateFocusStyle() {
 	c.MenuList.SetTitle("[::b]Options[-]")
 	c.MenuList2.SetBorder(false)
 	c.MenuList2.SetTitle("[::b] [-]")
+	c.MenuList3.SetBorder(false)
+	c.MenuList3.SetTitle("[::b] [-]")
 	c.ConnTable.SetBorderColor(tcell.ColorWhite)
-	c.ConnTable.SetTitle("[::b]Result Table[-]")
+	c.ConnTable.SetTitle(c.tableTitle())
 	c.ConnInfo.SetBorderColor(tcell.ColorWhite)
 	c.ConnInfo.SetTitle("[::b]Instance Info[-]")
 
@@ -190,12 +277,29 @@ func (c *Components) UpdateFocusStyle() {
 		c.MenuList2.SetTitle("[::b] [-]")
 	case c.ConnTable:
 		c.ConnTable.SetBorderColor(tcell.ColorWhite)
-		c.ConnTable.SetTitle("[::b]Result Table[-]")
+		c.ConnTable.SetTitle(c.tableTitle())
 	case c.ConnInfo:
 		c.ConnInfo.SetTitle("[::b]Instance Info[-]")
 	}
 }
 
+// tableTitle returns result table title including auto-refresh state
+func (c *Components) tableTitle() string {
+	title := "[::b]Result Table[-]"
+	if c.TableStatus != "" {
+		title += " [::d]" + tview.Escape(c.TableStatus) + "[-:-:-]"
+	}
+	if c.AutoRefresh > 0 {
+		title += fmt.Sprintf(" [green]⟳ %s[-]", c.AutoRefresh)
+	}
+	return title
+}
+
+// UpdateTableTitle refreshes the result table title after its status changed
+func (c *Components) UpdateTableTitle() {
+	c.ConnTable.SetTitle(c.tableTitle())
+}
+
 // DisplayConnections displays connection information in table
 func (c *Components) DisplayConnections(connections []model.Connection) {
 
@@ -272,68 +376,26 @@ func (c *Components) DisplayConnections(connections []model.Connection) {
 // DisplayTableStats displays table statistics in table
 func (c *Components) DisplayTableStats(tableStats []model.TableStat) {
 
-	c.ConnTable.Clear()
-
-	c.ConnTable.SetFixed(1, 0)
+	var rows [][]*tview.TableCell
+	for _, stat := range tableStats {
 
-
-	if len(c.TableHeaders) == 0 {
-	
-		c.TableHeaders = []string{"Schema", "Table Name", "Total Size", "Table Size", "Index Size", "Total Rows"}
-	}
-	
-	for i, header := range c.TableHeaders {
-		cell := tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false)
-		c.ConnTable.SetCell(0, i, cell)
-	}
-
-
-	if len(tableStats) == 0 {
-
-		cell := tview.NewTableCell("No table statistics").SetSelectable(true).SetExpansion(1)
-		cell.SetAlign(tview.AlignCenter)
-		c.ConnTable.SetCell(1, 0, cell)
-		
-
-		for i := 1; i < len(c.TableHeaders); i++ {
-			c.ConnTable.SetCell(1, i, tview.NewTableCell("").SetSelectable(true))
+		kind := relationKinds[stat.Kind]
+		if kind == "" {
+			kind = stat.Kind
 		}
-		
-
-		c.ConnTable.Select(1, 0)
-		return
-	}
-
-
-	for i, stat := range tableStats {
-		row := i + 1
-
-
-		c.ConnTable.SetCell(row, 0, tview.NewTableCell(stat.Schema))
-
-
-		c.ConnTable.SetCell(row, 1, tview.NewTableCell(stat.Name))
-
-
-		c.ConnTable.SetCell(row, 2, tview.NewTableCell(stat.TotalSize))
-
-
-		c.ConnTable.SetCell(row, 3, tview.NewTableCell(stat.TableSize))
-
 
-		c.ConnTable.SetCell(row, 4, tview.NewTableCell(stat.IndexSize))
-
-
-		c.ConnTable.SetCell(row, 5, tview.NewTableCell(formatInt64(stat.RowCount)))
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(stat.Schema),
+			tview.NewTableCell(stat.Name),
+			tview.NewTableCell(kind),
+			tview.NewTableCell(stat.TotalSize).SetAlign(tview.AlignRight),
+			tview.NewTableCell(stat.TableSize).SetAlign(tview.AlignRight),
+			tview.NewTableCell(stat.IndexSize).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt64(stat.RowCount)).SetAlign(tview.AlignRight),
+		})
 	}
-	
 
-	if len(tableStats) > 0 {
-		c.ConnTable.Select(1, 0)
-	}
-	
-
-	c.ConnTable.ScrollToBeginning()
+	c.displayRows(rows, "No table statistics")
 }
 
 
@@ -395,6 +457,10 @@ func (c *Components) DisplayCustomQueryResults(results [][]interface{}, headers
 
 		for j, value := range result {
 			if j < len(headers) {
+				if v, ok := value.(model.Value); ok {
+					c.ConnTable.SetCell(row, j, valueCell(v))
+					continue
+				}
 				cellValue := ""
 				if value != nil {
 					cellValue = fmt.Sprintf("%v", value)
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"p6s/internal/model"
+)
+
+// FormatDatabaseHealth formats database health for the Instance Info panel.
+// Rates are computed against prev, which is ignored when it belongs to another database.
+func FormatDatabaseHealth(cur, prev *model.DatabaseStat) string {
+	var b strings.Builder
+
+	b.WriteString("[yellow]Database Health:[white]\n")
+
+	usage := 0.0
+	if cur.MaxConnections > 0 {
+		usage = float64(cur.Connections) * 100 / float64(cur.MaxConnections)
+	}
+	b.WriteString(fmt.Sprintf("Connections: %s%d / %d (%.1f%%)[white]\n", thresholdColor(usage, 80, 95), cur.Connections, cur.MaxConnections, usage))
+
+	hitRatio := 100.0
+	if total := cur.BlksHit + cur.BlksRead; total > 0 {
+		hitRatio = float64(cur.BlksHit) * 100 / float64(total)
+	}
+	b.WriteString(fmt.Sprintf("Cache Hit: %s%.2f%%[white]\n", thresholdColor(100-hitRatio, 1, 10), hitRatio))
+
+	b.WriteString(fmt.Sprintf("Deadlocks: %d  Conflicts: %d\n", cur.Deadlocks, cur.Conflicts))
+	b.WriteString(fmt.Sprintf("Temp Files: %d (%s)\n", cur.TempFiles, formatBytes(cur.TempBytes)))
+
+	if prev == nil || prev.Database != cur.Database {
+		b.WriteString("[::d]Rates available after next refresh[-:-:-]\n")
+		return b.String()
+	}
+
+	elapsed := cur.CollectedAt.Sub(prev.CollectedAt).Seconds()
+	if elapsed <= 0 {
+		return b.String()
+	}
+	rate := func(curValue, prevValue int64) float64 {
+		// Counters go backwards after pg_stat_reset()
+		if curValue < prevValue {
+			return 0
+		}
+		return float64(curValue-prevValue) / elapsed
+	}
+
+	b.WriteString(fmt.Sprintf("Commits/s: %.1f  Rollbacks/s: %.1f\n", rate(cur.Commits, prev.Commits), rate(cur.Rollbacks, prev.Rollbacks)))
+
+	intervalHit := "-"
+	hits, reads := cur.BlksHit-prev.BlksHit, cur.BlksRead-prev.BlksRead
+	if hits >= 0 && reads >= 0 && hits+reads > 0 {
+		intervalHit = fmt.Sprintf("%.2f%%", float64(hits)*100/float64(hits+reads))
+	}
+	b.WriteString(fmt.Sprintf("Cache Hit (interval): %s\n", intervalHit))
+
+	if deadlocks := cur.Deadlocks - prev.Deadlocks; deadlocks > 0 {
+		b.WriteString(fmt.Sprintf("[red]New deadlocks: %d[white]\n", deadlocks))
+	}
+	b.WriteString(fmt.Sprintf("Temp/s: %.1f files, %s\n", rate(cur.TempFiles, prev.TempFiles), formatBytes(int64(rate(cur.TempBytes, prev.TempBytes)))))
+
+	return b.String()
+}
+
+// thresholdColor returns a color tag for value against warning and critical thresholds
+func thresholdColor(value, warning, critical float64) string {
+	switch {
+	case value >= critical:
+		return "[red]"
+	case value >= warning:
+		return "[yellow]"
+	default:
+		return "[white]"
+	}
+}
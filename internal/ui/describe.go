@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"p6s/internal/model"
+)
+
+// ExpandResults converts query results to psql's expanded display: one column/value row per field,
+// records separated by a "-[ RECORD n ]-" row
+func ExpandResults(results [][]interface{}, headers []string) ([][]interface{}, []string) {
+	var expanded [][]interface{}
+	for i, result := range results {
+		expanded = append(expanded, []interface{}{fmt.Sprintf("-[ RECORD %d ]-", i+1), ""})
+		for j, header := range headers {
+			var value interface{}
+			if j < len(result) {
+				value = result[j]
+			}
+			expanded = append(expanded, []interface{}{header, value})
+		}
+	}
+	return expanded, []string{"Column", "Value"}
+}
+
+// DescribeTableRows formats a table like psql's \d: one row per column followed by
+// index, constraint and trigger footers
+func DescribeTableRows(detail *model.TableDetail) ([][]interface{}, []string) {
+	headers := []string{"Column", "Type", "Collation", "Nullable", "Default"}
+
+	var rows [][]interface{}
+	for _, column := range detail.Columns {
+		nullable := ""
+		if column.NotNull {
+			nullable = "not null"
+		}
+		defaultValue := ""
+		switch {
+		case column.Identity == "a":
+			defaultValue = "generated always as identity"
+		case column.Identity == "d":
+			defaultValue = "generated by default as identity"
+		case column.Generated == "s":
+			defaultValue = "generated always as (" + column.Default.String + ") stored"
+		case column.Generated != "":
+			defaultValue = "generated always as (" + column.Default.String + ")"
+		case column.Default.Valid:
+			defaultValue = column.Default.String
+		}
+		rows = append(rows, []interface{}{column.Name, column.Type, formatNullString(column.Collation), nullable, defaultValue})
+	}
+
+	footer := func(title string, lines []string) {
+		for i, line := range lines {
+			label := ""
+			if i == 0 {
+				label = title
+			}
+			rows = append(rows, []interface{}{label, line, "", "", ""})
+		}
+	}
+
+	var indexes []string
+	for _, index := range detail.Indexes {
+		// Drop the "CREATE [UNIQUE] INDEX name ON table" part like psql does
+		definition := index.Definition
+		if using := strings.Index(definition, " USING "); using >= 0 {
+			definition = strings.TrimSpace(definition[using+len(" USING "):])
+		}
+		line := `"` + index.Name + `"`
+		switch {
+		case index.Primary:
+			line += " PRIMARY KEY,"
+		case index.Unique:
+			line += " UNIQUE,"
+		}
+		line += " " + definition
+		if !index.Valid {
+			line += " INVALID"
+		}
+		indexes = append(indexes, line)
+	}
+	footer("Indexes:", indexes)
+
+	var checks, foreignKeys []string
+	for _, constraint := range detail.Constraints {
+		switch constraint.Type {
+		case "CHECK", "EXCLUDE":
+			checks = append(checks, fmt.Sprintf(`"%s" %s`, constraint.Name, constraint.Definition))
+		case "FOREIGN KEY":
+			foreignKeys = append(foreignKeys, fmt.Sprintf(`"%s" %s`, constraint.Name, constraint.Definition))
+		}
+	}
+	footer("Check constraints:", checks)
+	footer("Foreign-key constraints:", foreignKeys)
+
+	var triggers []string
+	for _, trigger := range detail.Triggers {
+		triggers = append(triggers, trigger.Definition)
+	}
+	footer("Triggers:", triggers)
+
+	if detail.ViewDefinition.Valid {
+		footer("View definition:", strings.Split(strings.TrimSpace(detail.ViewDefinition.String), "\n"))
+	}
+	if detail.PartitionKey.Valid {
+		footer("Partition key:", []string{detail.PartitionKey.String})
+	}
+	if detail.PartitionOf.Valid {
+		footer("Partition of:", []string{detail.PartitionOf.String + " " + formatNullString(detail.PartitionBound)})
+	}
+
+	return rows, headers
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"p6s/internal/model"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// historyHeaders are the columns of the query history table
+var historyHeaders = []string{"Time", "Profile", "Database", "Duration", "Rows", "Status", "SQL"}
+
+// FillHistoryTable fills the query history table with entries, newest first as given
+func FillHistoryTable(table *tview.Table, entries []model.HistoryEntry) {
+	table.Clear()
+	table.SetFixed(1, 0)
+
+	for i, header := range historyHeaders {
+		table.SetCell(0, i, tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+
+	if len(entries) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No matching queries").SetSelectable(false))
+		return
+	}
+
+	for i, entry := range entries {
+		row := i + 1
+
+		status := tview.NewTableCell("ok").SetTextColor(tcell.ColorGreen)
+		if entry.Error != "" {
+			status = tview.NewTableCell("error").SetTextColor(tcell.ColorRed)
+		}
+
+		table.SetCell(row, 0, tview.NewTableCell(entry.Time.Local().Format("2006-01-02 15:04:05")))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(entry.Profile)))
+		table.SetCell(row, 2, tview.NewTableCell(tview.Escape(entry.Database)))
+		table.SetCell(row, 3, tview.NewTableCell(formatMillis(float64(entry.DurationMs))).SetAlign(tview.AlignRight))
+		table.SetCell(row, 4, tview.NewTableCell(formatHistoryRows(entry)).SetAlign(tview.AlignRight))
+		table.SetCell(row, 5, status)
+		table.SetCell(row, 6, tview.NewTableCell(tview.Escape(singleLine(entry.SQL))))
+	}
+	table.Select(1, 0)
+	table.ScrollToBeginning()
+}
+
+// formatHistoryRows formats the rows of a history entry like the script result list
+func formatHistoryRows(entry model.HistoryEntry) string {
+	switch {
+	case entry.Error != "":
+		return ""
+	case entry.Affected:
+		return fmt.Sprintf("%d affected", entry.Rows)
+	case entry.MoreRows:
+		return fmt.Sprintf("%d+", entry.Rows)
+	}
+	return formatInt64(entry.Rows)
+}
+
+// FormatHistoryEntry formats the full statement of a history entry and its error for the preview pane
+func FormatHistoryEntry(entry model.HistoryEntry) string {
+	text := tview.Escape(entry.SQL)
+	if len(entry.Params) > 0 {
+		names := make([]string, 0, len(entry.Params))
+		for name := range entry.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		text += "\n"
+		for _, name := range names {
+			text += fmt.Sprintf("\n[gray]:%s[-] = %s", name, tview.Escape(entry.Params[name]))
+		}
+	}
+	if entry.Error != "" {
+		text += "\n\n[red]" + tview.Escape(entry.Error) + "[-]"
+	}
+	return text
+}
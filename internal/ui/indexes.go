@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// DisplayIndexReport displays foreign keys without index followed by index usage in table,
+// indexes with issues are listed first
+func (c *Components) DisplayIndexReport(report *model.IndexReport) {
+
+	var rows [][]*tview.TableCell
+
+	for _, fk := range report.UnindexedForeignKeys {
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(fk.Schema + "." + fk.Table),
+			tview.NewTableCell(fk.Constraint),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell("foreign key"),
+			tview.NewTableCell("no index on (" + fk.Columns + ")").SetTextColor(tcell.ColorYellow),
+			tview.NewTableCell(fk.Definition),
+		})
+	}
+
+	for _, index := range report.Indexes {
+
+		kind := index.Method
+		switch {
+		case index.Primary:
+			kind = "primary"
+		case index.Unique:
+			kind = "unique " + index.Method
+		}
+
+		issueColor := tcell.ColorYellow
+		for _, issue := range index.Issues {
+			if issue == "invalid" || strings.HasPrefix(issue, "duplicate") {
+				issueColor = tcell.ColorRed
+			}
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(index.Schema + "." + index.Table),
+			tview.NewTableCell(index.Index),
+			tview.NewTableCell(formatInt64(index.Scans)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatBytes(index.Size)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(kind),
+			tview.NewTableCell(strings.Join(index.Issues, ", ")).SetTextColor(issueColor),
+			tview.NewTableCell(index.Definition),
+		})
+	}
+
+	c.displayRows(rows, "No user indexes")
+}
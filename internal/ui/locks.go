@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// DisplayLockTree displays blocker -> waiter lock tree in table
+func (c *Components) DisplayLockTree(nodes []model.LockNode) {
+
+	var rows [][]*tview.TableCell
+	for _, node := range nodes {
+
+		pidText := formatInt(node.PID)
+		color := tcell.ColorWhite
+		if node.Depth == 0 {
+			color = tcell.ColorRed
+		} else {
+			pidText = strings.Repeat("   ", node.Depth-1) + "└─ " + pidText
+		}
+
+		waiting := ""
+		if node.LockMode.Valid {
+			waiting = formatDuration(node.WaitDuration)
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(pidText).SetTextColor(color),
+			tview.NewTableCell(formatInt(node.RootPID)),
+			tview.NewTableCell(formatNullString(node.User)),
+			tview.NewTableCell(formatNullString(node.Database)),
+			tview.NewTableCell(formatNullString(node.LockType)),
+			tview.NewTableCell(formatNullString(node.LockMode)),
+			tview.NewTableCell(formatNullString(node.Relation)),
+			tview.NewTableCell(waiting),
+			tview.NewTableCell(formatNullString(node.State)),
+			tview.NewTableCell(singleLine(formatNullString(node.Query))),
+		})
+	}
+
+	c.displayRows(rows, "No blocked sessions")
+}
+
+// DisplayLocks displays pg_locks rows in table, ungranted locks are highlighted
+func (c *Components) DisplayLocks(locks []model.Lock) {
+
+	var rows [][]*tview.TableCell
+	for _, lock := range locks {
+
+		pid := ""
+		if lock.PID.Valid {
+			pid = formatInt64(lock.PID.Int64)
+		}
+
+		granted := "yes"
+		color := tcell.ColorWhite
+		if !lock.Granted {
+			granted = "no"
+			color = tcell.ColorRed
+		}
+
+		queryAge := ""
+		if lock.QueryAge.Valid {
+			queryAge = formatDuration(time.Duration(lock.QueryAge.Float64 * float64(time.Second)))
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(pid),
+			tview.NewTableCell(lock.LockType),
+			tview.NewTableCell(formatNullString(lock.Mode)),
+			tview.NewTableCell(granted).SetTextColor(color),
+			tview.NewTableCell(formatNullString(lock.Relation)),
+			tview.NewTableCell(formatNullString(lock.User)),
+			tview.NewTableCell(queryAge),
+			tview.NewTableCell(formatNullString(lock.State)),
+			tview.NewTableCell(singleLine(formatNullString(lock.Query))),
+		})
+	}
+
+	c.displayRows(rows, "No locks")
+}
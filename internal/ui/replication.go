@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// DisplayReplication displays standby progress, replicas and replication slots in table
+func (c *Components) DisplayReplication(status *model.ReplicationStatus) {
+
+	var rows [][]*tview.TableCell
+
+	if status.Recovery.InRecovery {
+		recovery := status.Recovery
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell("standby").SetTextColor(tcell.ColorYellow),
+			tview.NewTableCell(fmt.Sprintf("receive %s / replay %s", formatNullString(recovery.ReceiveLSN), formatNullString(recovery.ReplayLSN))),
+			tview.NewTableCell(formatNullString(recovery.ReceiverStatus)),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(formatLagSeconds(recovery.ReplayDelay)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatNullBytes(recovery.LagBytes)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(""),
+		})
+	}
+
+	for _, replica := range status.Replicas {
+		color := tcell.ColorWhite
+		if replica.State.String != "streaming" {
+			color = tcell.ColorYellow
+		}
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell("replica"),
+			tview.NewTableCell(fmt.Sprintf("%s@%s", formatNullString(replica.ApplicationName), formatNullString(replica.ClientAddr))),
+			tview.NewTableCell(formatNullString(replica.State)).SetTextColor(color),
+			tview.NewTableCell(formatNullString(replica.SyncState)),
+			tview.NewTableCell(formatLagSeconds(replica.WriteLag)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatLagSeconds(replica.FlushLag)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatLagSeconds(replica.ReplayLag)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatNullBytes(replica.LagBytes)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(""),
+		})
+	}
+
+	for _, slot := range status.Slots {
+		state, color := "active", tcell.ColorWhite
+		if !slot.Active {
+			// Inactive slots keep retaining WAL until they are dropped
+			state, color = "inactive", tcell.ColorRed
+		}
+		name := fmt.Sprintf("%s (%s)", slot.SlotName, slot.SlotType)
+		if slot.Database.Valid {
+			name = fmt.Sprintf("%s (%s, %s)", slot.SlotName, slot.SlotType, slot.Database.String)
+		}
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell("slot"),
+			tview.NewTableCell(name),
+			tview.NewTableCell(state).SetTextColor(color),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(formatNullBytes(slot.RetainedBytes)).SetAlign(tview.AlignRight),
+		})
+	}
+
+	c.displayRows(rows, "No replication configured")
+}
+
+// formatLagSeconds formats a lag given in seconds, empty when NULL
+func formatLagSeconds(seconds sql.NullFloat64) string {
+	if !seconds.Valid {
+		return ""
+	}
+	return formatDuration(time.Duration(seconds.Float64 * float64(time.Second)))
+}
+
+// formatNullBytes formats a byte count, empty when NULL
+func formatNullBytes(bytes sql.NullInt64) string {
+	if !bytes.Valid {
+		return ""
+	}
+	return formatBytes(bytes.Int64)
+}
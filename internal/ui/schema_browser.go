@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// schemaObjectGroups lists the object kinds below each schema of the schema tree in display order
+var schemaObjectGroups = []struct {
+	kind  string
+	title string
+}{
+	{model.ObjectTable, "Tables"},
+	{model.ObjectView, "Views"},
+	{model.ObjectMaterializedView, "Materialized Views"},
+	{model.ObjectFunction, "Functions"},
+	{model.ObjectSequence, "Sequences"},
+}
+
+// BuildSchemaTree builds the schema → object kind → object tree of the schema browser,
+// object nodes reference their *model.SchemaObject
+func BuildSchemaTree(database string, objects []model.SchemaObject) *tview.TreeNode {
+	root := tview.NewTreeNode(database).SetColor(tcell.ColorYellow).SetSelectable(false)
+
+	var schemas []string
+	bySchema := make(map[string]map[string][]*model.SchemaObject)
+	for i := range objects {
+		object := &objects[i]
+		if bySchema[object.Schema] == nil {
+			bySchema[object.Schema] = make(map[string][]*model.SchemaObject)
+			schemas = append(schemas, object.Schema)
+		}
+		bySchema[object.Schema][object.Kind] = append(bySchema[object.Schema][object.Kind], object)
+	}
+
+	for _, schema := range schemas {
+		schemaNode := tview.NewTreeNode(schema).SetColor(tcell.ColorGreen).SetExpanded(false)
+		for _, group := range schemaObjectGroups {
+			members := bySchema[schema][group.kind]
+			if len(members) == 0 {
+				continue
+			}
+			groupNode := tview.NewTreeNode(fmt.Sprintf("%s (%d)", group.title, len(members))).
+				SetColor(tcell.ColorDarkCyan).SetExpanded(false)
+			for _, object := range members {
+				groupNode.AddChild(tview.NewTreeNode(SchemaObjectLabel(object)).SetReference(object))
+			}
+			schemaNode.AddChild(groupNode)
+		}
+		root.AddChild(schemaNode)
+	}
+
+	return root
+}
+
+// SchemaObjectLabel returns the name of an object as shown in the schema tree, functions include their arguments
+func SchemaObjectLabel(object *model.SchemaObject) string {
+	if object.Kind == model.ObjectFunction {
+		return object.Name + "(" + object.Arguments + ")"
+	}
+	return object.Name
+}
+
+// FormatFunctionDetail formats the signature and source of a function for the schema browser
+func FormatFunctionDetail(detail *model.FunctionDetail) string {
+	var b strings.Builder
+
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("[yellow]%-12s[white]%s\n", name+":", tview.Escape(value)))
+	}
+
+	field("Function", fmt.Sprintf("%s.%s(%s)", detail.Schema, detail.Name, detail.Arguments))
+	if detail.Result.Valid {
+		field("Returns", detail.Result.String)
+	} else {
+		field("Returns", "procedure")
+	}
+	field("Language", detail.Language)
+	field("Owner", detail.Owner)
+
+	b.WriteString("\n[yellow]Definition:[white]\n")
+	b.WriteString(tview.Escape(detail.Definition))
+
+	return b.String()
+}
+
+// FormatSequenceDetail formats the settings and current value of a sequence for the schema browser
+func FormatSequenceDetail(detail *model.SequenceDetail) string {
+	var b strings.Builder
+
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("[yellow]%-14s[white]%s\n", name+":", tview.Escape(value)))
+	}
+
+	lastValue := "not called yet"
+	if detail.LastValue.Valid {
+		lastValue = fmt.Sprintf("%d", detail.LastValue.Int64)
+	}
+
+	field("Sequence", detail.Schema+"."+detail.Name)
+	field("Data Type", detail.DataType)
+	field("Current Value", lastValue)
+	field("Start", fmt.Sprintf("%d", detail.StartValue))
+	field("Increment", fmt.Sprintf("%d", detail.Increment))
+	field("Min / Max", fmt.Sprintf("%d / %d", detail.MinValue, detail.MaxValue))
+	field("Cache", fmt.Sprintf("%d", detail.CacheSize))
+	field("Cycle", fmt.Sprintf("%t", detail.Cycle))
+
+	if detail.LastValue.Valid && detail.Increment > 0 && detail.MaxValue > detail.MinValue {
+		used := float64(detail.LastValue.Int64-detail.MinValue) * 100 / float64(detail.MaxValue-detail.MinValue)
+		field("Used", fmt.Sprintf("%.4f%%", used))
+	}
+
+	return b.String()
+}
+
+// FuzzyScore matches pattern as a case-insensitive subsequence of text. Higher scores
+// are better: consecutive characters, matches at word starts and short texts are preferred.
+func FuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	lower := []rune(strings.ToLower(text))
+
+	score, pi, last := 0, 0, -2
+	for ti := 0; ti < len(lower) && pi < len(p); ti++ {
+		if lower[ti] != p[pi] {
+			continue
+		}
+		switch {
+		case ti == last+1:
+			score += 5
+		case ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) || unicode.IsUpper(t[ti]):
+			score += 3
+		default:
+			score++
+		}
+		last = ti
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, false
+	}
+	return score*100 - len(t), true
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"p6s/internal/model"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// statementStatusColors color the status column of the script results
+var statementStatusColors = map[string]tcell.Color{
+	model.StatementPending:   tcell.ColorGray,
+	model.StatementRunning:   tcell.ColorYellow,
+	model.StatementOK:        tcell.ColorGreen,
+	model.StatementFailed:    tcell.ColorRed,
+	model.StatementCancelled: tcell.ColorOrange,
+	model.StatementSkipped:   tcell.ColorGray,
+}
+
+// DisplayScriptResults displays one row per statement of a SQL script with its status, row count and duration
+func (c *Components) DisplayScriptResults(results []model.StatementResult) {
+
+	var rows [][]*tview.TableCell
+	for i, result := range results {
+
+		duration := ""
+		if result.Status != model.StatementPending && result.Status != model.StatementSkipped {
+			duration = formatMillis(float64(result.Duration.Microseconds()) / 1000)
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(formatInt(i + 1)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt(result.Line)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(result.Status).SetTextColor(statementStatusColors[result.Status]),
+			tview.NewTableCell(formatStatementRows(result)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(duration).SetAlign(tview.AlignRight),
+			tview.NewTableCell(tview.Escape(singleLine(result.SQL))),
+			tview.NewTableCell(tview.Escape(singleLine(result.Message))),
+		})
+	}
+
+	c.displayRows(rows, "No statements")
+}
+
+// formatStatementRows formats the fetched or affected rows of a script statement
+func formatStatementRows(result model.StatementResult) string {
+	switch {
+	case result.Affected:
+		return fmt.Sprintf("%d affected", result.Rows)
+	case result.Headers == nil:
+		return ""
+	case result.MoreRows:
+		return fmt.Sprintf("%d+", result.Rows)
+	}
+	return formatInt64(result.Rows)
+}
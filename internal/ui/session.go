@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// FormatSessionDetail formats a backend's activity, held locks and blockers for the session detail page
+func FormatSessionDetail(conn *model.Connection, locks []model.Lock, blockers []int) string {
+	var b strings.Builder
+
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("[yellow]%-18s[white]%s\n", name+":", tview.Escape(value)))
+	}
+
+	field("PID", formatInt(conn.PID))
+	field("User", formatNullString(conn.User))
+	field("Database", formatNullString(conn.Database))
+	field("Application", formatNullString(conn.ApplicationName))
+	field("Client Address", formatNullString(conn.ClientAddr))
+	field("Backend Type", formatNullString(conn.BackendType))
+	field("Status", formatNullString(conn.State))
+	field("Wait Event", formatWaitEvent(conn.WaitEventType, conn.WaitEvent))
+	b.WriteString("\n")
+
+	field("Backend Start", formatTime(conn.BackendStart)+" ("+formatDuration(time.Since(conn.BackendStart))+" ago)")
+	field("Transaction Start", formatNullTimeAge(conn.XactStart))
+	field("Query Start", formatNullTimeAge(conn.QueryStart))
+	field("State Change", formatNullTimeAge(conn.StateChange))
+	field("Backend XID", formatNullString(conn.BackendXid))
+	field("Backend XMIN", formatNullString(conn.BackendXmin))
+	b.WriteString("\n")
+
+	if len(blockers) == 0 {
+		field("Blocked By", "none")
+	} else {
+		pids := make([]string, len(blockers))
+		for i, pid := range blockers {
+			pids[i] = formatInt(pid)
+		}
+		b.WriteString(fmt.Sprintf("[yellow]%-18s[red]%s[white]\n", "Blocked By:", strings.Join(pids, ", ")))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(fmt.Sprintf("[yellow]Locks (%d):[white]\n", len(locks)))
+	for _, lock := range locks {
+		granted := "granted"
+		if !lock.Granted {
+			granted = "[red]waiting[white]"
+		}
+		b.WriteString(fmt.Sprintf("  %-26s %-14s %-40s %s\n",
+			formatNullString(lock.Mode), lock.LockType, tview.Escape(formatNullString(lock.Relation)), granted))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("[yellow]Query:[white]\n")
+	b.WriteString(tview.Escape(FormatSQL(formatNullString(conn.Query))))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// formatWaitEvent formats wait_event_type and wait_event as "type: event"
+func formatWaitEvent(eventType, event sql.NullString) string {
+	if !eventType.Valid {
+		return ""
+	}
+	return eventType.String + ": " + event.String
+}
+
+// formatNullTimeAge formats a timestamp together with its age, empty when NULL
+func formatNullTimeAge(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return formatTime(t.Time) + " (" + formatDuration(time.Since(t.Time)) + " ago)"
+}
+
+// DisplayLongTransactions displays sessions ordered by transaction age, idle in transaction sessions are highlighted
+func (c *Components) DisplayLongTransactions(connections []model.Connection) {
+
+	var rows [][]*tview.TableCell
+	for _, conn := range connections {
+
+		color := tcell.ColorWhite
+		if strings.HasPrefix(conn.State.String, "idle in transaction") {
+			color = tcell.ColorRed
+		}
+
+		xactAge := ""
+		if conn.XactStart.Valid {
+			xactAge = formatDuration(time.Since(conn.XactStart.Time))
+		}
+		stateAge := ""
+		if conn.StateChange.Valid {
+			stateAge = formatDuration(time.Since(conn.StateChange.Time))
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(formatInt(conn.PID)),
+			tview.NewTableCell(formatNullString(conn.User)),
+			tview.NewTableCell(formatNullString(conn.Database)),
+			tview.NewTableCell(formatNullString(conn.ApplicationName)),
+			tview.NewTableCell(formatNullString(conn.State)).SetTextColor(color),
+			tview.NewTableCell(xactAge).SetAlign(tview.AlignRight),
+			tview.NewTableCell(stateAge).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatNullString(conn.BackendXmin)),
+			tview.NewTableCell(singleLine(formatNullString(conn.Query))),
+		})
+	}
+
+	c.displayRows(rows, "No long-running or idle in transaction sessions")
+}
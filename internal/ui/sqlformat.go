@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// clauseKeywords start a new line when formatting SQL
+var clauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true,
+	"HAVING": true, "LIMIT": true, "OFFSET": true, "UNION": true, "EXCEPT": true,
+	"INTERSECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "SET": true,
+	"VALUES": true, "RETURNING": true, "WITH": true, "WINDOW": true,
+}
+
+// joinPrefixes start a new line when followed by JOIN or OUTER
+var joinPrefixes = map[string]bool{
+	"LEFT": true, "RIGHT": true, "INNER": true, "FULL": true, "CROSS": true, "NATURAL": true,
+}
+
+// FormatSQL reflows a query for display: clauses start on their own line, AND/OR
+// conditions are indented and nesting follows parentheses. Literals, quoted
+// identifiers and comments are kept verbatim.
+func FormatSQL(query string) string {
+	tokens := tokenizeSQL(query)
+
+	var b strings.Builder
+	depth := 0
+	newLine := func(extra int) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+			b.WriteString(strings.Repeat("  ", depth+extra))
+		}
+	}
+
+	prev := ""
+	inBetween := false
+	for i, tok := range tokens {
+		upper := strings.ToUpper(tok)
+		next := ""
+		if i+1 < len(tokens) {
+			next = strings.ToUpper(tokens[i+1])
+		}
+
+		switch {
+		case clauseKeywords[upper] && !(upper == "SET" && prev == "UPDATE"):
+			newLine(0)
+		case joinPrefixes[upper] && (next == "JOIN" || next == "OUTER"):
+			newLine(0)
+		case upper == "JOIN" && !joinPrefixes[prev] && prev != "OUTER":
+			newLine(0)
+		case upper == "AND" && inBetween:
+			// AND of BETWEEN x AND y stays on the line
+			inBetween = false
+			b.WriteString(" ")
+		case upper == "AND" || upper == "OR" || upper == "ON":
+			newLine(1)
+		case strings.HasPrefix(prev, "--"):
+			newLine(0)
+		case b.Len() > 0 && needsSpace(prev, tok):
+			b.WriteString(" ")
+		}
+
+		b.WriteString(tok)
+
+		if upper == "BETWEEN" {
+			inBetween = true
+		}
+
+		switch tok {
+		case "(":
+			depth++
+		case ")":
+			if depth > 0 {
+				depth--
+			}
+		}
+		prev = upper
+	}
+
+	return b.String()
+}
+
+// needsSpace reports whether a space separates two adjacent tokens
+func needsSpace(prev, tok string) bool {
+	switch {
+	case prev == "(" || prev == "." || prev == "::":
+		return false
+	case tok == ")" || tok == "," || tok == ";" || tok == "." || tok == "::":
+		return false
+	case tok == "(" && prev != "" && isWordToken(prev) && !clauseKeywords[prev] && prev != "AND" && prev != "OR" && prev != "IN" && prev != "AS":
+		// function call
+		return false
+	}
+	return true
+}
+
+// isWordToken reports whether tok is an identifier or keyword
+func isWordToken(tok string) bool {
+	r := []rune(tok)
+	return len(r) > 0 && (unicode.IsLetter(r[0]) || r[0] == '_')
+}
+
+// tokenizeSQL splits a query into words, literals, comments and punctuation, dropping whitespace
+func tokenizeSQL(query string) []string {
+	var tokens []string
+	r := []rune(query)
+	n := len(r)
+
+	for i := 0; i < n; {
+		c := r[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '-' && i+1 < n && r[i+1] == '-':
+			for i < n && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < n && r[i+1] == '*':
+			end := strings.Index(string(r[i+2:]), "*/")
+			if end < 0 {
+				i = n
+			} else {
+				i += 2 + len([]rune(string(r[i+2:])[:end])) + 2
+			}
+		case c == '\'' || c == '"':
+			i++
+			for i < n {
+				if r[i] == c {
+					// doubled quote is an escaped quote
+					if i+1 < n && r[i+1] == c {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+		case c == '$' && dollarTag(r, i) != "":
+			tag := dollarTag(r, i)
+			i += len([]rune(tag))
+			end := strings.Index(string(r[i:]), tag)
+			if end < 0 {
+				i = n
+			} else {
+				i += len([]rune(string(r[i:])[:end])) + len([]rune(tag))
+			}
+		case c == ':' && i+1 < n && r[i+1] == ':':
+			i += 2
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '$':
+			for i < n && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) || r[i] == '_' || r[i] == '$') {
+				i++
+			}
+		default:
+			i++
+		}
+
+		tokens = append(tokens, string(r[start:i]))
+	}
+
+	return tokens
+}
+
+// dollarTag returns the dollar quote tag starting at r[i], e.g. "$$" or "$body$", or "" if none
+func dollarTag(r []rune, i int) string {
+	j := i + 1
+	for j < len(r) && (unicode.IsLetter(r[j]) || r[j] == '_' || (j > i+1 && unicode.IsDigit(r[j]))) {
+		j++
+	}
+	if j < len(r) && r[j] == '$' {
+		return string(r[i : j+1])
+	}
+	return ""
+}
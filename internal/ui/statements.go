@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// DisplayTopQueries displays pg_stat_statements rows in table
+func (c *Components) DisplayTopQueries(queries []model.TopQuery) {
+
+	var rows [][]*tview.TableCell
+	for _, q := range queries {
+
+		hitRatio := ""
+		if total := q.SharedBlksHit + q.SharedBlksRead; total > 0 {
+			hitRatio = fmt.Sprintf("%.1f%%", float64(q.SharedBlksHit)*100/float64(total))
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(formatInt64(q.Calls)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatMillis(q.TotalTime)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatMillis(q.MeanTime)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt64(q.Rows)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt64(q.SharedBlksHit)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt64(q.SharedBlksRead)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(hitRatio).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt64(q.TempBlksRead + q.TempBlksWritten)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatNullString(q.User)),
+			tview.NewTableCell(singleLine(formatNullString(q.Query))),
+		})
+	}
+
+	c.displayRows(rows, "No statements recorded")
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// displayRows renders header and prepared cell rows into the result table
+func (c *Components) displayRows(rows [][]*tview.TableCell, emptyText string) {
+
+	c.ConnTable.Clear()
+
+	c.ConnTable.SetFixed(1, 0)
+
+	for i, header := range c.TableHeaders {
+		column := i
+		cell := tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false)
+		cell.SetClickedFunc(func() bool {
+			if c.HeaderClicked != nil {
+				c.HeaderClicked(column)
+			}
+			return true
+		})
+		c.ConnTable.SetCell(0, i, cell)
+	}
+
+	if len(rows) == 0 {
+
+		cell := tview.NewTableCell(emptyText).SetSelectable(true).SetExpansion(1)
+		cell.SetAlign(tview.AlignCenter)
+		c.ConnTable.SetCell(1, 0, cell)
+
+		for i := 1; i < len(c.TableHeaders); i++ {
+			c.ConnTable.SetCell(1, i, tview.NewTableCell("").SetSelectable(true))
+		}
+
+		c.ConnTable.Select(1, 0)
+		return
+	}
+
+	for i, cells := range rows {
+		for j, cell := range cells {
+			c.ConnTable.SetCell(i+1, j, cell)
+		}
+	}
+
+	c.ConnTable.Select(1, 0)
+
+	c.ConnTable.ScrollToBeginning()
+}
+
+// DisplayMessage displays a single message row under the current headers
+func (c *Components) DisplayMessage(text string) {
+	c.displayRows(nil, text)
+}
+
+// formatMillis formats milliseconds, switching to seconds for long durations
+func formatMillis(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.2fms", ms)
+}
+
+// formatBytes formats a byte count using binary units like pg_size_pretty
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit && bytes > -unit {
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+	value := float64(bytes)
+	for _, suffix := range []string{"kB", "MB", "GB", "TB"} {
+		value /= unit
+		if value < unit && value > -unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%.1f PB", value/unit)
+}
+
+// formatDuration formats a duration as a compact human readable string
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// singleLine collapses whitespace so multi-line queries fit in one table cell
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// relationKinds maps pg_class.relkind to a readable name
+var relationKinds = map[string]string{
+	"r": "table",
+	"p": "partitioned table",
+	"m": "materialized view",
+	"v": "view",
+	"f": "foreign table",
+	"t": "TOAST table",
+}
+
+// FormatTableDetail formats structure, sizes, activity and DDL of a table for the table detail page
+func FormatTableDetail(detail *model.TableDetail) string {
+	var b strings.Builder
+
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("[yellow]%-18s[white]%s\n", name+":", tview.Escape(value)))
+	}
+	section := func(title string, count int) {
+		b.WriteString(fmt.Sprintf("\n[yellow]%s (%d):[white]\n", title, count))
+	}
+
+	kind := relationKinds[detail.Kind]
+	if kind == "" {
+		kind = detail.Kind
+	}
+	if detail.Persistence == "u" {
+		kind = "unlogged " + kind
+	}
+
+	field("Table", detail.Schema+"."+detail.Name)
+	field("Kind", kind)
+	field("Owner", detail.Owner)
+	if detail.Comment.Valid {
+		field("Comment", detail.Comment.String)
+	}
+	if detail.Options != "" {
+		field("Options", detail.Options)
+	}
+	if detail.PartitionOf.Valid {
+		field("Partition Of", detail.PartitionOf.String+" "+formatNullString(detail.PartitionBound))
+	}
+	b.WriteString("\n")
+
+	field("Total Size", formatBytes(detail.TotalSize))
+	field("Table Size", formatBytes(detail.TableSize))
+	field("Index Size", formatBytes(detail.IndexSize))
+	field("TOAST Size", formatBytes(detail.ToastSize))
+	field("Rows (estimate)", formatInt64(detail.RowEstimate))
+	b.WriteString("\n")
+
+	if detail.HasStats {
+		field("Seq Scans", fmt.Sprintf("%s (%s tuples read)", formatInt64(detail.SeqScan), formatInt64(detail.SeqTupRead)))
+		field("Index Scans", fmt.Sprintf("%s (%s tuples fetched)", formatInt64(detail.IdxScan), formatInt64(detail.IdxTupFetch)))
+		field("Inserted", formatInt64(detail.TupInserted))
+		field("Updated", fmt.Sprintf("%s (%s HOT%s)", formatInt64(detail.TupUpdated), formatInt64(detail.TupHotUpdated), formatHotRatio(detail)))
+		field("Deleted", formatInt64(detail.TupDeleted))
+		field("Live / Dead", fmt.Sprintf("%s / %s", formatInt64(detail.LiveTuples), formatInt64(detail.DeadTuples)))
+	} else {
+		field("Activity", "no statistics collected for this relation")
+	}
+
+	section("Columns", len(detail.Columns))
+	for _, column := range detail.Columns {
+		attributes := ""
+		if column.NotNull {
+			attributes += " not null"
+		}
+		if column.Identity != "" {
+			attributes += " identity"
+		} else if column.Generated != "" {
+			attributes += " generated: " + column.Default.String
+		} else if column.Default.Valid {
+			attributes += " default " + column.Default.String
+		}
+		if column.Comment.Valid {
+			attributes += "  -- " + column.Comment.String
+		}
+		b.WriteString(fmt.Sprintf("  %-30s %-28s%s\n", tview.Escape(column.Name), tview.Escape(column.Type), tview.Escape(attributes)))
+	}
+
+	section("Indexes", len(detail.Indexes))
+	for _, index := range detail.Indexes {
+		status := ""
+		if !index.Valid {
+			status = " [red]INVALID[white]"
+		}
+		b.WriteString(fmt.Sprintf("  %s (%s, %s scans)%s\n    [::d]%s[-:-:-]\n",
+			tview.Escape(index.Name), formatBytes(index.Size), formatInt64(index.Scans), status, tview.Escape(index.Definition)))
+	}
+
+	section("Constraints", len(detail.Constraints))
+	for _, constraint := range detail.Constraints {
+		b.WriteString(fmt.Sprintf("  %-30s %-12s %s\n", tview.Escape(constraint.Name), constraint.Type, tview.Escape(constraint.Definition)))
+	}
+
+	section("Triggers", len(detail.Triggers))
+	for _, trigger := range detail.Triggers {
+		status := ""
+		if trigger.Enabled == "D" {
+			status = " [red]disabled[white]"
+		}
+		b.WriteString(fmt.Sprintf("  %s%s\n    [::d]%s[-:-:-]\n", tview.Escape(trigger.Name), status, tview.Escape(trigger.Definition)))
+	}
+
+	b.WriteString("\n[yellow]DDL:[white]\n")
+	b.WriteString(tview.Escape(FormatCreateTable(detail)))
+
+	return b.String()
+}
+
+// FormatCreateTable reconstructs the DDL of a table including its indexes, triggers and comments
+func FormatCreateTable(detail *model.TableDetail) string {
+	var b strings.Builder
+
+	kind := "TABLE"
+	switch detail.Kind {
+	case "v":
+		kind = "VIEW"
+	case "m":
+		kind = "MATERIALIZED VIEW"
+	}
+
+	switch {
+	case detail.ViewDefinition.Valid:
+		b.WriteString(fmt.Sprintf("CREATE %s %s AS\n%s\n", kind, detail.QualifiedName, strings.TrimSpace(detail.ViewDefinition.String)))
+
+	case detail.PartitionOf.Valid:
+		// Partitions inherit columns and constraints from their parent
+		b.WriteString(fmt.Sprintf("CREATE TABLE %s PARTITION OF %s\n    %s", detail.QualifiedName, detail.PartitionOf.String, formatNullString(detail.PartitionBound)))
+		if detail.PartitionKey.Valid {
+			b.WriteString("\n    PARTITION BY " + detail.PartitionKey.String)
+		}
+		b.WriteString(";\n")
+
+	default:
+		persistence := ""
+		if detail.Persistence == "u" {
+			persistence = "UNLOGGED "
+		}
+		b.WriteString(fmt.Sprintf("CREATE %sTABLE %s (\n", persistence, detail.QualifiedName))
+
+		var lines []string
+		for _, column := range detail.Columns {
+			line := "    " + column.QuotedName + " " + column.Type
+			if column.Collation.Valid {
+				line += " COLLATE " + column.Collation.String
+			}
+			switch {
+			case column.Identity == "a":
+				line += " GENERATED ALWAYS AS IDENTITY"
+			case column.Identity == "d":
+				line += " GENERATED BY DEFAULT AS IDENTITY"
+			case column.Generated == "s":
+				line += " GENERATED ALWAYS AS (" + column.Default.String + ") STORED"
+			case column.Generated != "":
+				line += " GENERATED ALWAYS AS (" + column.Default.String + ")"
+			case column.Default.Valid:
+				line += " DEFAULT " + column.Default.String
+			}
+			if column.NotNull {
+				line += " NOT NULL"
+			}
+			lines = append(lines, line)
+		}
+		for _, constraint := range detail.Constraints {
+			lines = append(lines, "    CONSTRAINT "+constraint.QuotedName+" "+constraint.Definition)
+		}
+		b.WriteString(strings.Join(lines, ",\n"))
+		b.WriteString("\n)")
+
+		if detail.PartitionKey.Valid {
+			b.WriteString("\nPARTITION BY " + detail.PartitionKey.String)
+		}
+		if detail.Options != "" {
+			b.WriteString("\nWITH (" + detail.Options + ")")
+		}
+		b.WriteString(";\n")
+	}
+
+	for _, index := range detail.Indexes {
+		// Constraint indexes are created by their constraint
+		if index.ConstraintOf {
+			continue
+		}
+		b.WriteString(index.Definition + ";\n")
+	}
+
+	for _, trigger := range detail.Triggers {
+		b.WriteString(trigger.Definition + ";\n")
+	}
+
+	if detail.Comment.Valid {
+		b.WriteString(fmt.Sprintf("COMMENT ON %s %s IS %s;\n", kind, detail.QualifiedName, quoteLiteral(detail.Comment.String)))
+	}
+	for _, column := range detail.Columns {
+		if column.Comment.Valid {
+			b.WriteString(fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;\n", detail.QualifiedName, column.QuotedName, quoteLiteral(column.Comment.String)))
+		}
+	}
+
+	return b.String()
+}
+
+// formatHotRatio formats the share of HOT updates as ", n%", empty without updates
+func formatHotRatio(detail *model.TableDetail) string {
+	if detail.TupUpdated == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %.1f%%", float64(detail.TupHotUpdated)*100/float64(detail.TupUpdated))
+}
+
+// quoteLiteral quotes s as a SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// DisplayVacuumStatus displays running vacuums followed by per-table dead tuples and autovacuum thresholds in table
+func (c *Components) DisplayVacuumStatus(status *model.VacuumStatus) {
+
+	var rows [][]*tview.TableCell
+
+	for _, progress := range status.Running {
+		kind := "vacuum"
+		if progress.Autovacuum {
+			kind = "autovacuum"
+		}
+		elapsed := ""
+		if progress.Duration.Valid {
+			elapsed = ", " + formatDuration(time.Duration(progress.Duration.Float64*float64(time.Second)))
+		}
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(progress.Table).SetTextColor(tcell.ColorYellow),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(""),
+			tview.NewTableCell(fmt.Sprintf("%s %s (%s pid %d, index passes %d%s)",
+				formatProgressBar(vacuumPercent(progress), 20), progress.Phase, kind, progress.PID, progress.IndexVacuumCount, elapsed)).SetTextColor(tcell.ColorYellow),
+		})
+	}
+
+	for _, table := range status.Tables {
+
+		deadRatio := ""
+		if total := table.LiveTuples + table.DeadTuples; total > 0 {
+			deadRatio = fmt.Sprintf("%.1f%%", float64(table.DeadTuples)*100/float64(total))
+		}
+
+		// Autovacuum picks the table up once dead tuples exceed the threshold
+		deadColor := tcell.ColorWhite
+		if float64(table.DeadTuples) > table.VacuumThreshold {
+			deadColor = tcell.ColorYellow
+			if !table.AutovacuumEnabled {
+				deadColor = tcell.ColorRed
+			}
+		}
+
+		vacuumAt := tview.NewTableCell(fmt.Sprintf("%.0f", table.VacuumThreshold)).SetAlign(tview.AlignRight)
+		analyzeAt := tview.NewTableCell(fmt.Sprintf("%.0f", table.AnalyzeThreshold)).SetAlign(tview.AlignRight)
+		if !table.AutovacuumEnabled {
+			vacuumAt.SetText("disabled").SetTextColor(tcell.ColorRed)
+			analyzeAt.SetText("disabled").SetTextColor(tcell.ColorRed)
+		}
+
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(table.Schema + "." + table.Table),
+			tview.NewTableCell(formatInt64(table.LiveTuples)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(formatInt64(table.DeadTuples)).SetAlign(tview.AlignRight).SetTextColor(deadColor),
+			tview.NewTableCell(deadRatio).SetAlign(tview.AlignRight).SetTextColor(deadColor),
+			vacuumAt,
+			tview.NewTableCell(formatInt64(table.ModsSinceAnalyze)).SetAlign(tview.AlignRight),
+			analyzeAt,
+			tview.NewTableCell(formatSince(table.LastVacuum)),
+			tview.NewTableCell(formatSince(table.LastAutovacuum)),
+			tview.NewTableCell(formatSince(table.LastAnalyze)),
+			tview.NewTableCell(formatSince(table.LastAutoanalyze)),
+			tview.NewTableCell(""),
+		})
+	}
+
+	c.displayRows(rows, "No user tables")
+}
+
+// vacuumPercent returns the progress of the heap phase a vacuum is currently in
+func vacuumPercent(progress model.VacuumProgress) float64 {
+	if progress.HeapBlksTotal <= 0 {
+		return 0
+	}
+	done := progress.HeapBlksScanned
+	if progress.Phase == "vacuuming heap" {
+		done = progress.HeapBlksVacuumed
+	}
+	return float64(done) * 100 / float64(progress.HeapBlksTotal)
+}
+
+// formatProgressBar renders a percentage as a fixed width bar
+func formatProgressBar(percent float64, width int) string {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := int(percent / 100 * float64(width))
+	return fmt.Sprintf("%s%s %5.1f%%", strings.Repeat("█", filled), strings.Repeat("░", width-filled), percent)
+}
+
+// formatSince formats how long ago a timestamp was, "never" when NULL
+func formatSince(t sql.NullTime) string {
+	if !t.Valid {
+		return "never"
+	}
+	return formatDuration(time.Since(t.Time)) + " ago"
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"p6s/internal/model"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// numericTypes are right-aligned in the result table
+var numericTypes = map[string]bool{
+	"INT2": true, "INT4": true, "INT8": true, "OID": true,
+	"FLOAT4": true, "FLOAT8": true, "NUMERIC": true, "MONEY": true,
+}
+
+// FormatValue formats a query result value on a single line, the way psql prints it
+func FormatValue(v model.Value) string {
+	return formatValue(v, false)
+}
+
+// FormatValuePretty formats a query result value for a detail view, indenting JSON
+func FormatValuePretty(v model.Value) string {
+	return formatValue(v, true)
+}
+
+// formatValue formats the driver value according to its PostgreSQL type
+func formatValue(v model.Value, pretty bool) string {
+	switch data := v.Data.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(data, 10)
+	case float64:
+		bits := 64
+		if v.Type == "FLOAT4" {
+			bits = 32
+		}
+		return strconv.FormatFloat(data, 'g', -1, bits)
+	case bool:
+		if data {
+			return "true"
+		}
+		return "false"
+	case time.Time:
+		return formatTimeValue(v.Type, data)
+	case []byte:
+		return formatBytesValue(v.Type, data, pretty)
+	case string:
+		return data
+	}
+	return fmt.Sprintf("%v", v.Data)
+}
+
+// formatTimeValue formats date and time values. The driver returns them in the session TimeZone
+// reported by the server, so the offset shown is the one of the session.
+func formatTimeValue(typeName string, t time.Time) string {
+	switch typeName {
+	case "DATE":
+		return t.Format("2006-01-02")
+	case "TIME":
+		return t.Format("15:04:05.999999")
+	case "TIMETZ":
+		return t.Format("15:04:05.999999") + formatUTCOffset(t)
+	case "TIMESTAMP":
+		return t.Format("2006-01-02 15:04:05.999999")
+	}
+	return t.Format("2006-01-02 15:04:05.999999") + formatUTCOffset(t)
+}
+
+// formatUTCOffset formats the UTC offset of t like PostgreSQL: +02, -03:30
+func formatUTCOffset(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	hours, minutes, seconds := offset/3600, offset%3600/60, offset%60
+	switch {
+	case seconds != 0:
+		return fmt.Sprintf("%s%02d:%02d:%02d", sign, hours, minutes, seconds)
+	case minutes != 0:
+		return fmt.Sprintf("%s%02d:%02d", sign, hours, minutes)
+	}
+	return fmt.Sprintf("%s%02d", sign, hours)
+}
+
+// formatBytesValue formats values the driver returns as bytes: bytea as hex, JSON compacted or
+// indented, everything else (numeric, uuid, interval, arrays, ...) in its text representation
+func formatBytesValue(typeName string, data []byte, pretty bool) string {
+	switch typeName {
+	case "BYTEA":
+		return `\x` + hex.EncodeToString(data)
+	case "JSON", "JSONB":
+		var buf bytes.Buffer
+		var err error
+		if pretty {
+			err = json.Indent(&buf, data, "", "  ")
+		} else {
+			err = json.Compact(&buf, data)
+		}
+		if err == nil {
+			return buf.String()
+		}
+	}
+	if !utf8.Valid(data) {
+		return `\x` + hex.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// valueCell builds the result table cell of a value: NULL dimmed, numbers right-aligned
+func valueCell(v model.Value) *tview.TableCell {
+	if v.IsNull() {
+		return tview.NewTableCell("NULL").
+			SetTextColor(tcell.ColorDarkGray).
+			SetAttributes(tcell.AttrDim | tcell.AttrItalic)
+	}
+
+	// Table cells hold a single line
+	text := strings.ReplaceAll(FormatValue(v), "\n", "↵")
+	cell := tview.NewTableCell(tview.Escape(text))
+	if numericTypes[v.Type] {
+		cell.SetAlign(tview.AlignRight)
+	}
+	return cell
+}
+
+// FormatResultRow formats one row of query results for the row detail page, one column per paragraph
+func FormatResultRow(headers []string, row []interface{}) string {
+	var sb strings.Builder
+	for i, header := range headers {
+		var value interface{}
+		if i < len(row) {
+			value = row[i]
+		}
+
+		text := ""
+		switch v := value.(type) {
+		case model.Value:
+			if v.IsNull() {
+				text = "[gray::d]NULL[-:-:-]"
+			} else {
+				text = tview.Escape(FormatValuePretty(v))
+			}
+		case nil:
+		default:
+			text = tview.Escape(fmt.Sprintf("%v", v))
+		}
+
+		typeName := ""
+		if v, ok := value.(model.Value); ok && v.Type != "" {
+			typeName = " [gray]" + tview.Escape(typeLabel(v.Type)) + "[-]"
+		}
+		sb.WriteString(fmt.Sprintf("[yellow::b]%s[-:-:-]%s\n%s\n\n", tview.Escape(header), typeName, text))
+	}
+	return sb.String()
+}
+
+// typeLabel converts a driver type name to the PostgreSQL spelling, e.g. _INT4 to int4[]
+func typeLabel(typeName string) string {
+	typeName = strings.ToLower(typeName)
+	if strings.HasPrefix(typeName, "_") {
+		return typeName[1:] + "[]"
+	}
+	return typeName
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"p6s/internal/model"
+)
+
+// Percentages of autovacuum_freeze_max_age at which wraparound ages are highlighted,
+// anti-wraparound autovacuums are forced from 100% on
+const (
+	WraparoundWarningPercent  = 75
+	WraparoundCriticalPercent = 100
+)
+
+// DisplayWraparound displays database and table xid/multixact ages against the freeze limits in table
+func (c *Components) DisplayWraparound(status *model.WraparoundStatus) {
+
+	var rows [][]*tview.TableCell
+
+	ageCells := func(xidAge, mxidAge int64) []*tview.TableCell {
+		xidPercent := agePercent(xidAge, status.FreezeMaxAge)
+		mxidPercent := agePercent(mxidAge, status.MultixactFreezeMaxAge)
+		return []*tview.TableCell{
+			tview.NewTableCell(formatInt64(xidAge)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%.1f%%", xidPercent)).SetAlign(tview.AlignRight).SetTextColor(wraparoundColor(xidPercent)),
+			tview.NewTableCell(formatInt64(mxidAge)).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%.1f%%", mxidPercent)).SetAlign(tview.AlignRight).SetTextColor(wraparoundColor(mxidPercent)),
+		}
+	}
+
+	for _, database := range status.Databases {
+		row := []*tview.TableCell{
+			tview.NewTableCell("database"),
+			tview.NewTableCell(database.Database),
+		}
+		row = append(row, ageCells(database.XidAge, database.MxidAge)...)
+		rows = append(rows, append(row, tview.NewTableCell("")))
+	}
+
+	for _, table := range status.Tables {
+		row := []*tview.TableCell{
+			tview.NewTableCell("table"),
+			tview.NewTableCell(table.Schema + "." + table.Table),
+		}
+		row = append(row, ageCells(table.XidAge, table.MxidAge)...)
+		rows = append(rows, append(row, tview.NewTableCell(formatBytes(table.Size)).SetAlign(tview.AlignRight)))
+	}
+
+	c.displayRows(rows, "No databases")
+}
+
+// FormatWraparoundWarning returns a warning line for the Instance Info panel when a database age
+// crosses the warning threshold, empty otherwise
+func FormatWraparoundWarning(status *model.WraparoundStatus) string {
+	var worst model.DatabaseAge
+	worstPercent := 0.0
+	for _, database := range status.Databases {
+		percent := agePercent(database.XidAge, status.FreezeMaxAge)
+		if mxidPercent := agePercent(database.MxidAge, status.MultixactFreezeMaxAge); mxidPercent > percent {
+			percent = mxidPercent
+		}
+		if percent > worstPercent {
+			worst, worstPercent = database, percent
+		}
+	}
+
+	if worstPercent < WraparoundWarningPercent {
+		return ""
+	}
+
+	return fmt.Sprintf("%sWraparound: %s at %.0f%% of freeze max age (xid %s, mxid %s)[white]\n",
+		thresholdColor(worstPercent, WraparoundWarningPercent, WraparoundCriticalPercent),
+		worst.Database, worstPercent, formatInt64(worst.XidAge), formatInt64(worst.MxidAge))
+}
+
+// agePercent returns age as percentage of the freeze limit
+func agePercent(age, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(age) * 100 / float64(limit)
+}
+
+// wraparoundColor returns the text color of an age percentage
+func wraparoundColor(percent float64) tcell.Color {
+	switch {
+	case percent >= WraparoundCriticalPercent:
+		return tcell.ColorRed
+	case percent >= WraparoundWarningPercent:
+		return tcell.ColorYellow
+	default:
+		return tcell.ColorWhite
+	}
+}